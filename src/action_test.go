@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePage stands in for the game page: it runs the keyboardEvent() scripts
+// Action injects and tracks which keys the game sees held down
+type fakePage struct {
+	mu        sync.Mutex
+	down      map[string]bool
+	failUntil map[string]int // Releases of a key that fail before one succeeds
+}
+
+func newFakePage() *fakePage {
+	return &fakePage{down: make(map[string]bool), failUntil: make(map[string]int)}
+}
+
+func (p *fakePage) evaluate(ctx context.Context, js string, res any) error {
+	var mode, key string
+	if _, err := fmt.Sscanf(strings.ReplaceAll(js, "'", " "), "keyboardEvent( %s , %s );", &mode, &key); err != nil {
+		return nil // Not a key event
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch mode {
+	case "hold":
+		p.down[key] = true
+	case "release":
+		if p.failUntil[key] > 0 {
+			p.failUntil[key]--
+			return fmt.Errorf("release %s failed", key)
+		}
+		delete(p.down, key)
+	}
+	return nil
+}
+
+// heldDown returns the keys the game sees held down, sorted
+func (p *fakePage) heldDown() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := make([]string, 0, len(p.down))
+	for key := range p.down {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newFakeAction returns an Action driving page instead of a browser
+func newFakeAction(page *fakePage) *Action {
+	browser := &Browser{ctx: context.Background(), evaluate: page.evaluate}
+	return NewAction(browser, nil)
+}
+
+func TestReleaseAllHeld(t *testing.T) {
+	page := newFakePage()
+	action := newFakeAction(page)
+
+	// Walking forward and jumping, then the bot is stopped mid-move
+	for _, key := range []string{"w", "space", "d"} {
+		if err := action.SendKey(key, KeyHold); err != nil {
+			t.Fatalf("SendKey(%s, KeyHold) failed: %v", key, err)
+		}
+	}
+	if err := action.SendKey("d", KeyRelease); err != nil {
+		t.Fatalf("SendKey(d, KeyRelease) failed: %v", err)
+	}
+	if down := page.heldDown(); strings.Join(down, ",") != "space,w" {
+		t.Fatalf("keys held down in the game before stop = %v, want [space w]", down)
+	}
+
+	if got := action.ReleaseAllHeld(); got != 2 {
+		t.Errorf("ReleaseAllHeld() = %d, want 2", got)
+	}
+	if down := page.heldDown(); len(down) != 0 {
+		t.Errorf("keys still held down in the game after stop: %v", down)
+	}
+	if held := action.HeldKeys(); len(held) != 0 {
+		t.Errorf("HeldKeys() after stop = %v, want none", held)
+	}
+	if got := action.ReleaseAllHeld(); got != 0 {
+		t.Errorf("second ReleaseAllHeld() = %d, want 0", got)
+	}
+}
+
+func TestReleaseAllHeldCaseInsensitive(t *testing.T) {
+	page := newFakePage()
+	action := newFakeAction(page)
+
+	// "W" and "w" are the same key: holding both leaves one key to release
+	action.SendKey("W", KeyHold)
+	action.SendKey("w", KeyHold)
+
+	if held := action.HeldKeys(); len(held) != 1 {
+		t.Errorf("HeldKeys() = %v, want one key", held)
+	}
+	if got := action.ReleaseAllHeld(); got != 1 {
+		t.Errorf("ReleaseAllHeld() = %d, want 1", got)
+	}
+}
+
+func TestReleaseAllHeldRetriesFailedRelease(t *testing.T) {
+	page := newFakePage()
+	action := newFakeAction(page)
+
+	action.SendKey("w", KeyHold)
+	action.SendKey("shift", KeyHold)
+	page.failUntil["shift"] = 1
+
+	if got := action.ReleaseAllHeld(); got != 1 {
+		t.Errorf("ReleaseAllHeld() = %d, want 1", got)
+	}
+	if held := action.HeldKeys(); len(held) != 1 || held[0] != "shift" {
+		t.Fatalf("HeldKeys() after failed release = %v, want [shift]", held)
+	}
+
+	if got := action.ReleaseAllHeld(); got != 1 {
+		t.Errorf("retried ReleaseAllHeld() = %d, want 1", got)
+	}
+	if down := page.heldDown(); len(down) != 0 {
+		t.Errorf("keys still held down in the game after retry: %v", down)
+	}
+}
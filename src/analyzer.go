@@ -6,11 +6,18 @@
 // Key responsibilities:
 //   - Screen capture and caching
 //   - HP/MP/FP bar detection via HSV color masking
-//   - Mob name detection (passive/aggressive/violet) using HSV
-//   - Target marker detection (red/blue) using HSV
-//   - Target distance calculation
+//     (full detection every N frames, cached bar areas re-scanned in between)
+//   - Mob nameplate template matching (see templates.go); the pixel
+//     scanning detections (mob names, target marker, target distance,
+//     drops, party bars) live in pixelscan.go
+//   - "Inventory full" system message detection
+//   - Skill cast bar detection
+//   - Pickup pet buff icon detection (template match)
 //   - Contour-based detection for improved accuracy
 //
+// The OpenCV (gocv) work runs in a Detector (see detector.go), so the bot
+// can be built without OpenCV (-tags nocv) with detection disabled.
+//
 // Detection Pipeline:
 //   1. Capture screen image
 //   2. Convert to HSV color space
@@ -22,10 +29,9 @@
 package main
 
 import (
+	"fmt"
 	"image"
 	"sync"
-
-	"gocv.io/x/gocv"
 )
 
 // Note: Color, Bounds, Target, MobType, and other basic types are defined in data.go
@@ -37,16 +43,80 @@ type ImageAnalyzer struct {
 	screenInfo *ScreenInfo
 	lastImage  *image.RGBA
 	stats      *ClientStats
+	mobNames   *MobNameHistory // Recently recognized mob names
+	detector   Detector        // OpenCV detections (stub without OpenCV, see detector.go)
 	mu         sync.RWMutex
+
+	// Incremental status bar detection
+	recalibrateInterval int // Frames between full status bar detections
+	statusFrames        int // Frames since the last full detection
+	statusFailures      int // Consecutive failed incremental detections
+
+	// Static frame skipping (see framediff.go)
+	staticFrame bool            // Current frame matches the last processed one, detections reuse their results
+	staticSkips int             // Consecutive static frames
+	frameSig    *frameSignature // Signature of the last processed frame (nil = none)
+	barAreas    []image.Rectangle
+	lastMobs    []Target // IdentifyMobs result of the last processed frame
 }
 
+// maxStatusFailures is the number of consecutive incremental failures that force a full detection
+const maxStatusFailures = 5
+
 // NewImageAnalyzer creates a new image analyzer with OpenCV support
 func NewImageAnalyzer(browser *Browser) *ImageAnalyzer {
 	bounds := browser.GetScreenBounds()
+	screenInfo := NewScreenInfo(bounds)
 	return &ImageAnalyzer{
 		browser:    browser,
-		screenInfo: NewScreenInfo(bounds),
+		screenInfo: screenInfo,
 		stats:      NewClientStats(),
+		mobNames:   NewMobNameHistory(10),
+		detector:   newDetector(screenInfo),
+		barAreas:   statusBarAreas(),
+
+		recalibrateInterval: 30,
+	}
+}
+
+// SetStatusRecalibrateInterval sets how many frames pass between full status
+// bar detections. Values below 1 are treated as 1 (full detection every frame).
+func (ia *ImageAnalyzer) SetStatusRecalibrateInterval(frames int) {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+	ia.recalibrateInterval = max(frames, 1)
+}
+
+// SetPickupPetIcon loads the pickup pet's buff bar icon, matched every frame to
+// tell whether the pet is summoned ("" disables the detection)
+func (ia *ImageAnalyzer) SetPickupPetIcon(path string) {
+	ia.detector.SetPickupPetIcon(path)
+}
+
+// SetPartyInviteTemplate loads the party invite dialog, matched every frame to
+// answer invites with Config.PartyInviteAction ("" disables the detection)
+func (ia *ImageAnalyzer) SetPartyInviteTemplate(path string) {
+	ia.detector.SetPartyInviteTemplate(path)
+}
+
+// SetActionBar sets the action bar whose icons are read every frame for
+// cooldown sweeps (Config.ReadActionBarCooldowns, nil disables the detection)
+func (ia *ImageAnalyzer) SetActionBar(area *Bounds) {
+	ia.detector.SetActionBar(area)
+}
+
+// SetDebuffIcons loads the debuff icons (Config.DebuffIcons) matched every
+// frame to tell whether the character is stunned or rooted (empty disables
+// the detection)
+func (ia *ImageAnalyzer) SetDebuffIcons(icons map[string]string) {
+	ia.detector.SetDebuffIcons(icons)
+}
+
+// SetMessageTemplates loads the on-screen message templates of the game
+// language (messages.json), events without one are read with OCR
+func (ia *ImageAnalyzer) SetMessageTemplates(messages *GameMessages) {
+	for _, event := range []string{MessageInventoryFull, MessageOutOfRange} {
+		ia.detector.SetMessageTemplate(event, messages.Template(event))
 	}
 }
 
@@ -67,6 +137,39 @@ func (ia *ImageAnalyzer) Capture() error {
 	return nil
 }
 
+// SetFrame stores a captured frame. With skipStatic, a frame differing from the
+// last processed frame by less than threshold (mean luminance, status bar areas
+// per cell) is marked static, and UpdateStats and IdentifyMobs reuse their
+// previous results for it. At most staticMaxSkips frames in a row are static.
+func (ia *ImageAnalyzer) SetFrame(img *image.RGBA, skipStatic bool, threshold float64) {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+
+	ia.lastImage = img
+	ia.staticFrame = false
+	if !skipStatic {
+		ia.frameSig = nil
+		return
+	}
+
+	sig := newFrameSignature(img, ia.barAreas)
+	if ia.staticSkips < staticMaxSkips && sig.isStatic(ia.frameSig, threshold) {
+		ia.staticFrame = true
+		ia.staticSkips++
+		LogDebug("Static frame (%d/%d), reusing detection results", ia.staticSkips, staticMaxSkips)
+		return
+	}
+	ia.frameSig = sig
+	ia.staticSkips = 0
+}
+
+// IsStaticFrame reports whether the current frame was marked static by SetFrame
+func (ia *ImageAnalyzer) IsStaticFrame() bool {
+	ia.mu.RLock()
+	defer ia.mu.RUnlock()
+	return ia.staticFrame
+}
+
 // GetImage returns the last captured image
 func (ia *ImageAnalyzer) GetImage() *image.RGBA {
 	ia.mu.RLock()
@@ -82,48 +185,87 @@ func (ia *ImageAnalyzer) GetStats() *ClientStats {
 // UpdateStats updates all client stats from the current image using OpenCV
 func (ia *ImageAnalyzer) UpdateStats() {
 	img := ia.GetImage()
-	if img == nil {
+	if img == nil || ia.IsStaticFrame() {
 		return
 	}
 
-	// Convert image.RGBA to gocv.Mat
-	mat := ia.imageToMat(img)
-	if mat.Empty() {
-		return
-	}
-	defer mat.Close()
+	// Decide between a full detection and an incremental update of the cached bar areas
+	ia.mu.Lock()
+	ia.statusFrames++
+	full := ia.statusFrames >= ia.recalibrateInterval ||
+		ia.statusFailures >= maxStatusFailures ||
+		!ia.stats.HasCachedBars()
+	ia.mu.Unlock()
 
-	// Convert to HSV color space
-	hsvMat := gocv.NewMat()
-	defer hsvMat.Close()
-	gocv.CvtColor(mat, &hsvMat, gocv.ColorBGRToHSV)
+	ok := ia.detector.UpdateStats(img, ia.stats, full)
+	ia.stats.SetTargetOnScreenDetected(ia.DetectTargetMarker())
 
-	// Update HP/MP/FP bars using OpenCV HSV detection
-	ia.stats.UpdateOpenCV(&hsvMat)
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+	if full {
+		ia.statusFrames = 0
+		ia.statusFailures = 0
+	} else if ok {
+		ia.statusFailures = 0
+	} else {
+		ia.statusFailures++
+		LogDebug("Incremental status bar detection failed (%d/%d)", ia.statusFailures, maxStatusFailures)
+	}
 }
 
+// CalibrateBars samples the HP/MP/FP bar colors from the last captured frame.
+// The bars must be full. Bars that cannot be sampled are left out of the result.
+func (ia *ImageAnalyzer) CalibrateBars() (map[string]HSVRange, error) {
+	img := ia.GetImage()
+	if img == nil {
+		return nil, fmt.Errorf("no frame captured yet")
+	}
+	return ia.detector.CalibrateBars(img, ia.stats)
+}
 
+// RecentMobNames returns recently recognized mob names, most recent first
+func (ia *ImageAnalyzer) RecentMobNames() []string {
+	return ia.mobNames.Names()
+}
 
 // DetectTargetHP detects the target's HP value (returns 0-100)
 func (ia *ImageAnalyzer) DetectTargetHP() int {
 	return ia.stats.TargetHP.Value
 }
 
-// Helper methods
-
-// imageToMat converts image.RGBA to gocv.Mat (BGR format for OpenCV)
-func (ia *ImageAnalyzer) imageToMat(img *image.RGBA) gocv.Mat {
-	if img == nil {
-		return gocv.NewMat()
-	}
+// identifyMobsByTemplate detects mobs with the nameplate templates in config.MobTemplateDir.
+// Templates are loaded on first use. The name color decides the mob type.
+func (ia *ImageAnalyzer) identifyMobsByTemplate(img *image.RGBA, region Bounds, config *Config) []Target {
+	config.mu.RLock()
+	dir := config.MobTemplateDir
+	threshold := config.MobTemplateThreshold
+	keepViolet := config.PrioritizeViolet
+	config.mu.RUnlock()
+	minY := config.Detection().MobMinY
 
-	// Convert RGBA to BGR for OpenCV
-	mat, err := gocv.ImageToMatRGB(img)
-	if err != nil {
-		LogError("Failed to convert image to mat: %v", err)
-		return gocv.NewMat()
+	mobs, templates := ia.detector.MatchTemplates(img, region, dir, threshold)
+	filtered := make([]Target, 0, len(mobs))
+	for _, mob := range mobs {
+		if mob.Bounds.Y < minY {
+			continue
+		}
+		switch ia.detectNameColorAt(mob.Bounds, config) {
+		case NameColorAggressive:
+			mob.Type = MobAggressive
+		case NameColorViolet:
+			if !keepViolet {
+				LogDebug("Template %s matched a violet name at (%d,%d), filtering out", mob.Name, mob.Bounds.X, mob.Bounds.Y)
+				continue
+			}
+			mob.Type = MobViolet
+		case NameColorNPC:
+			LogDebug("Template %s matched an NPC name at (%d,%d), filtering out", mob.Name, mob.Bounds.X, mob.Bounds.Y)
+			continue
+		}
+		ia.mobNames.Add(mob.Name)
+		filtered = append(filtered, mob)
 	}
 
-	return mat
+	LogDebug("Identified %d mobs by template (%d templates)", len(filtered), templates)
+	return filtered
 }
-
@@ -0,0 +1,214 @@
+// Package main - api.go
+//
+// Optional local HTTP API for remote monitoring and control.
+// Started when Config.APIPort > 0, shut down together with the browser.
+//
+// Endpoints:
+//   - GET  /status: current mode, behavior state, HP/MP/FP, kills, abandoned steals, KPM, uptime and
+//     kill times per mob (JSON)
+//   - POST /mode:   switch mode, body {"mode":"Stop"} (Stop/Farming/Support/Shouting),
+//     queued for the main loop and answered with 202 Accepted
+//
+// If Config.APIToken is set, every request must carry "Authorization: Bearer <token>".
+// Without a token the API only listens on 127.0.0.1.
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIStatus is the JSON body returned by GET /status
+type APIStatus struct {
+	Mode   string  `json:"mode"`
+	Stage  string  `json:"stage"`
+	Halted string  `json:"halted,omitempty"`
+	HP     int     `json:"hp"`
+	MP     int     `json:"mp"`
+	FP     int     `json:"fp"`
+	Kills  int     `json:"kills"`
+	Steals int     `json:"steals"` // Targets abandoned to other players
+	KPM    float64 `json:"kpm"`
+	Uptime string  `json:"uptime"`
+
+	MobKillTimes map[string]APIKillTime `json:"mob_kill_times,omitempty"`
+	SlowKills    string                 `json:"slow_kills,omitempty"` // Mob over Config.SlowKillThresholdMs
+}
+
+// APIKillTime is the kill time distribution of one mob name in milliseconds
+type APIKillTime struct {
+	Kills     int   `json:"kills"`
+	MinMs     int64 `json:"min_ms"`
+	MaxMs     int64 `json:"max_ms"`
+	AvgMs     int64 `json:"avg_ms"`
+	RollingMs int64 `json:"rolling_ms"` // Average of the last kills
+}
+
+// apiModeRequest is the JSON body accepted by POST /mode
+type apiModeRequest struct {
+	Mode string `json:"mode"`
+}
+
+// apiModes are the modes accepted by POST /mode
+var apiModes = []string{"Stop", "Farming", "Support", "Shouting"}
+
+// APIServer serves the status/control API
+type APIServer struct {
+	bot    *Bot
+	token  string
+	server *http.Server
+}
+
+// NewAPIServer creates an API server listening on port, on all interfaces if
+// token is set and on 127.0.0.1 otherwise
+func NewAPIServer(bot *Bot, port int, token string) *APIServer {
+	api := &APIServer{
+		bot:   bot,
+		token: token,
+	}
+
+	host := ""
+	if token == "" {
+		host = "127.0.0.1"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", api.authorize(api.handleStatus))
+	mux.HandleFunc("/mode", api.authorize(api.handleMode))
+
+	api.server = &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", host, port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return api
+}
+
+// Start runs the server in the background
+func (api *APIServer) Start() {
+	if api.token == "" {
+		LogWarn("API token is empty, the HTTP API only accepts local connections")
+	}
+
+	go func() {
+		LogInfo("HTTP API listening on %s", api.server.Addr)
+		err := api.server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			LogError("HTTP API stopped: %v", err)
+		}
+	}()
+}
+
+// Shutdown stops the server, waiting up to 3 seconds for active requests
+func (api *APIServer) Shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := api.server.Shutdown(ctx); err != nil {
+		LogWarn("HTTP API shutdown failed: %v", err)
+		return
+	}
+	LogInfo("HTTP API stopped")
+}
+
+// authorize wraps a handler with the bearer token check
+func (api *APIServer) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if api.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(api.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// handleStatus serves GET /status
+func (api *APIServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b := api.bot
+	kills, kpm, _, uptime, _ := b.stats.GetStats()
+	clientStats := b.analyzer.GetStats()
+	stage, halted := b.BehaviorStatus()
+
+	status := APIStatus{
+		Mode:   b.config.GetMode(),
+		Stage:  stage,
+		Halted: halted,
+		HP:     clientStats.GetHPPercent(),
+		MP:     clientStats.GetMPPercent(),
+		FP:     clientStats.GetFPPercent(),
+		Kills:  kills,
+		Steals: b.stats.GetStealsAbandoned(),
+		KPM:    kpm,
+		Uptime: uptime,
+
+		MobKillTimes: make(map[string]APIKillTime),
+	}
+	for name, killTime := range b.stats.GetMobKillTimes() {
+		status.MobKillTimes[name] = APIKillTime{
+			Kills:     killTime.Kills,
+			MinMs:     killTime.Min.Milliseconds(),
+			MaxMs:     killTime.Max.Milliseconds(),
+			AvgMs:     killTime.Average().Milliseconds(),
+			RollingMs: killTime.RollingAverage().Milliseconds(),
+		}
+	}
+	status.SlowKills, _ = b.stats.GetSlowKillMob()
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleMode serves POST /mode
+func (api *APIServer) handleMode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req apiModeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	valid := false
+	for _, mode := range apiModes {
+		if req.Mode == mode {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		http.Error(w, fmt.Sprintf("unknown mode %q", req.Mode), http.StatusBadRequest)
+		return
+	}
+
+	LogInfo("Mode change requested via HTTP API: %s", req.Mode)
+	api.bot.RequestMode(req.Mode)
+
+	// Applied by the main loop between iterations
+	writeJSON(w, http.StatusAccepted, apiModeRequest{Mode: req.Mode})
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		LogDebug("Failed to write API response: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+// Package main - buffs.go
+//
+// Duration-based self-buff scheduling.
+// Buff slots with a duration in Config.BuffDurations are recast shortly before
+// the buff runs out, instead of whenever the slot cooldown allows it. Buff slots
+// without a duration keep the old behavior (cast while waiting between kills).
+//
+// Casts are only known from the bot's own key presses, so the schedule is reset
+// on death, when every buff is lost.
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// buffRecastLead is how long before a buff expires it is recast
+const buffRecastLead = 5 * time.Second
+
+// BuffScheduler tracks when each scheduled buff slot was last cast
+type BuffScheduler struct {
+	lastCast map[int]time.Time // slot number -> last cast time
+	mu       sync.Mutex
+}
+
+// NewBuffScheduler creates an empty buff schedule (every buff is due)
+func NewBuffScheduler() *BuffScheduler {
+	return &BuffScheduler{
+		lastCast: make(map[int]time.Time),
+	}
+}
+
+// Due returns the first slot of slots whose buff expires within buffRecastLead
+// (or was never cast). Slots without a duration are skipped.
+func (bs *BuffScheduler) Due(slots []int, durations map[int]int) (int, bool) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	for _, slot := range slots {
+		seconds, ok := durations[slot]
+		if !ok || seconds <= 0 {
+			continue
+		}
+		lastCast, cast := bs.lastCast[slot]
+		if !cast || time.Since(lastCast) >= time.Duration(seconds)*time.Second-buffRecastLead {
+			return slot, true
+		}
+	}
+	return -1, false
+}
+
+// MarkCast records that a buff slot was just cast
+func (bs *BuffScheduler) MarkCast(slot int) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.lastCast[slot] = time.Now()
+}
+
+// Reset forgets all casts, making every buff due again
+func (bs *BuffScheduler) Reset() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if len(bs.lastCast) > 0 {
+		LogInfo("Buff schedule reset")
+	}
+	bs.lastCast = make(map[int]time.Time)
+}
+
+// untimedBuffSlots returns the buff slots without a configured duration
+func untimedBuffSlots(slots []int, durations map[int]int) []int {
+	result := make([]int, 0, len(slots))
+	for _, slot := range slots {
+		if durations[slot] <= 0 {
+			result = append(result, slot)
+		}
+	}
+	return result
+}
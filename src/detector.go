@@ -0,0 +1,64 @@
+// Package main - detector.go
+//
+// This file declares the Detector interface, which holds every detection that
+// needs OpenCV (gocv). ImageAnalyzer calls it instead of using gocv directly.
+//
+// Implementations (selected by build tag):
+//   - detector_opencv.go (default): OpenCV detections, needs the OpenCV runtime
+//   - detector_stub.go (-tags nocv): no OpenCV needed; detections log
+//     "detection unavailable" once and return empty results
+//
+// Building without OpenCV:
+//
+//	go build -tags nocv
+//
+// The stub build still runs the tray, browser, HTTP API and cookie saving, so
+// a machine without OpenCV can log in and keep its session. Mode behaviors see
+// no status bars; the pixel scanning detections of pixelscan.go (mob name
+// colors, target marker, drops) need no OpenCV and keep working. It also lets
+// behavior logic be built and tested without OpenCV installed.
+package main
+
+import "image"
+
+// Detector runs the OpenCV based detections of ImageAnalyzer
+type Detector interface {
+	// Available reports whether detection works in this build
+	Available() bool
+
+	// UpdateStats updates stats from img: status bars, "inventory full",
+	// popups, cast bar, chat input, pickup pet icon, party invite dialog,
+	// action bar cooldowns and watched debuff icons.
+	// full requests a full status bar detection instead of re-scanning the
+	// cached bar areas.
+	// Returns false if an incremental update failed and a full one is needed.
+	UpdateStats(img *image.RGBA, stats *ClientStats, full bool) bool
+
+	// CalibrateBars samples the HP/MP/FP bar colors from img (bars must be full)
+	CalibrateBars(img *image.RGBA, stats *ClientStats) (map[string]HSVRange, error)
+
+	// SetPickupPetIcon loads the pickup pet buff icon ("" disables the detection)
+	SetPickupPetIcon(path string)
+
+	// SetPartyInviteTemplate loads the party invite dialog ("" disables the detection)
+	SetPartyInviteTemplate(path string)
+
+	// SetActionBar sets the action bar read for cooldown sweeps (nil disables the detection)
+	SetActionBar(area *Bounds)
+
+	// SetDebuffIcons loads the debuff icons (name -> path) that impair control
+	// (empty disables the detection)
+	SetDebuffIcons(icons map[string]string)
+
+	// SetMessageTemplate loads the template of an on-screen message event
+	// (messages.go), matched instead of its text color ("" = color detection)
+	SetMessageTemplate(event, path string)
+
+	// MatchMessage matches the template of a message event inside region.
+	// ok is false if the event has no template.
+	MatchMessage(img *image.RGBA, region Bounds, event string) (matched, ok bool)
+
+	// MatchTemplates finds nameplates from the templates in dir inside region.
+	// Returns the matches and the number of loaded templates.
+	MatchTemplates(img *image.RGBA, region Bounds, dir string, threshold float32) ([]Target, int)
+}
@@ -0,0 +1,691 @@
+//go:build !nocv
+
+// Package main - detector_opencv.go
+//
+// This file implements the Detector with OpenCV (gocv), the default build.
+// It holds the frame-wide checks run on every UpdateStats (inventory full,
+// popups, cast bar, chat input, pickup pet icon, party invite dialog, action
+// bar cooldowns, debuff icons) and owns
+// the nameplate TemplateMatcher.
+package main
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// cvDetector is the OpenCV Detector
+type cvDetector struct {
+	screenInfo *ScreenInfo
+	petIcon    *gocv.Mat            // Grayscale pickup pet buff icon (nil = detection disabled)
+	inviteDlg  *gocv.Mat            // Grayscale party invite dialog (nil = detection disabled)
+	actionBar  *Bounds              // Action bar read for cooldown sweeps, 800x600 base (nil = detection disabled)
+	debuffs    map[string]*gocv.Mat // Grayscale debuff icons impairing control per name (empty = detection disabled)
+	messages   map[string]*gocv.Mat // Grayscale on-screen message templates per event (missing = OCR of the text)
+	templates  *TemplateMatcher     // Nameplate templates (loaded on first template-mode detection)
+	fullReadAt time.Time            // When the inventory full band was last read with OCR
+	fullRead   bool                 // Whether that read found the "inventory full" text
+	mu         sync.Mutex
+}
+
+// newDetector creates the OpenCV detector
+func newDetector(screenInfo *ScreenInfo) Detector {
+	return &cvDetector{screenInfo: screenInfo}
+}
+
+// Available always reports true
+func (d *cvDetector) Available() bool {
+	return true
+}
+
+// UpdateStats updates stats from img using OpenCV HSV detection
+func (d *cvDetector) UpdateStats(img *image.RGBA, stats *ClientStats, full bool) bool {
+	// Convert image.RGBA to gocv.Mat
+	mat := imageToMat(img)
+	if mat.Empty() {
+		return false
+	}
+	defer mat.Close()
+
+	// Convert to HSV color space
+	hsvMat := gocv.NewMat()
+	defer hsvMat.Close()
+	gocv.CvtColor(mat, &hsvMat, gocv.ColorBGRToHSV)
+
+	// Check for the "inventory full" system message, by template if the game
+	// language has one, otherwise by its text
+	d.mu.Lock()
+	if template := d.messages[MessageInventoryFull]; template != nil {
+		stats.SetInventoryFullDetected(d.matchMessage(&mat, d.screenInfo.ScaleBounds(inventoryFullBand), template))
+	} else {
+		stats.SetInventoryFullDetected(d.detectInventoryFull(img, &hsvMat))
+	}
+	d.mu.Unlock()
+
+	// Check for popups (level up rewards, stat window) covering the play area
+	stats.SetPopupDetected(d.detectPopup(&hsvMat))
+
+	// Check for the skill cast bar
+	stats.SetCastingDetected(d.detectCastBar(&hsvMat))
+
+	// Check for the open chat input box
+	stats.SetChatFocusDetected(d.detectChatInput(&hsvMat))
+
+	// Check the buff bar for the pickup pet icon
+	d.mu.Lock()
+	if d.petIcon != nil {
+		stats.SetPickupPetDetected(d.detectPickupPet(&mat))
+	}
+
+	// Check for the party invite dialog
+	if d.inviteDlg != nil {
+		stats.SetPartyInviteDetected(d.detectPartyInvite(&mat))
+	}
+
+	// Check the action bar icons for cooldown sweeps
+	if d.actionBar != nil {
+		stats.SetSlotCoolingDetected(d.detectActionBarCooldowns(&hsvMat))
+	}
+
+	// Check the buff bar for stun/root icons
+	if len(d.debuffs) > 0 {
+		stats.SetControlImpairedDetected(d.detectDebuffs(&mat))
+	}
+	d.mu.Unlock()
+
+	if !full {
+		return stats.UpdateOpenCVCached(&hsvMat)
+	}
+
+	// Update HP/MP/FP bars using OpenCV HSV detection
+	stats.UpdateOpenCV(&hsvMat)
+	return true
+}
+
+// CalibrateBars samples the HP/MP/FP bar colors from img
+func (d *cvDetector) CalibrateBars(img *image.RGBA, stats *ClientStats) (map[string]HSVRange, error) {
+	mat := imageToMat(img)
+	if mat.Empty() {
+		return nil, fmt.Errorf("failed to convert frame")
+	}
+	defer mat.Close()
+
+	hsvMat := gocv.NewMat()
+	defer hsvMat.Close()
+	gocv.CvtColor(mat, &hsvMat, gocv.ColorBGRToHSV)
+
+	colors := make(map[string]HSVRange)
+	for _, bar := range []*StatInfo{stats.HP, stats.MP, stats.FP} {
+		colorRange, ok := bar.SampleColorRange(&hsvMat)
+		if !ok {
+			LogWarn("Bar calibration: %s bar not found", bar.StatKind.String())
+			continue
+		}
+		colors[bar.StatKind.String()] = colorRange
+	}
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("no status bar found, is the status tray open?")
+	}
+	return colors, nil
+}
+
+// SetPickupPetIcon loads the pickup pet's buff bar icon ("" disables the detection)
+func (d *cvDetector) SetPickupPetIcon(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.petIcon = replaceTemplate(d.petIcon, path, "pickup pet icon")
+}
+
+// SetPartyInviteTemplate loads the party invite dialog ("" disables the detection)
+func (d *cvDetector) SetPartyInviteTemplate(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inviteDlg = replaceTemplate(d.inviteDlg, path, "party invite template")
+}
+
+// SetActionBar sets the action bar read for cooldown sweeps (nil disables the detection)
+func (d *cvDetector) SetActionBar(area *Bounds) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.actionBar = area
+}
+
+// SetDebuffIcons loads the debuff icons impairing control (empty disables the detection)
+func (d *cvDetector) SetDebuffIcons(icons map[string]string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name, icon := range d.debuffs {
+		icon.Close()
+		delete(d.debuffs, name)
+	}
+	for name, path := range icons {
+		if icon := replaceTemplate(nil, path, name+" debuff icon"); icon != nil {
+			if d.debuffs == nil {
+				d.debuffs = make(map[string]*gocv.Mat)
+			}
+			d.debuffs[name] = icon
+		}
+	}
+}
+
+// SetMessageTemplate loads the template of an on-screen message event ("" = color detection)
+func (d *cvDetector) SetMessageTemplate(event, path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.messages == nil {
+		d.messages = make(map[string]*gocv.Mat)
+	}
+	if template := replaceTemplate(d.messages[event], path, event+" message template"); template != nil {
+		d.messages[event] = template
+	} else {
+		delete(d.messages, event)
+	}
+}
+
+// MatchMessage matches the template of a message event inside region (800x600 base)
+func (d *cvDetector) MatchMessage(img *image.RGBA, region Bounds, event string) (bool, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	template := d.messages[event]
+	if template == nil {
+		return false, false
+	}
+
+	mat := imageToMat(img)
+	if mat.Empty() {
+		return false, true
+	}
+	defer mat.Close()
+	return d.matchMessage(&mat, d.screenInfo.ScaleBounds(region), template), true
+}
+
+// messageThreshold is the minimum normalized correlation for a message template
+const messageThreshold = 0.8
+
+// matchMessage matches a grayscale message template inside area (screen
+// coordinates). Must be called with d.mu held.
+func (d *cvDetector) matchMessage(mat *gocv.Mat, area Bounds, template *gocv.Mat) bool {
+	minX, minY := max(area.X, 0), max(area.Y, 0)
+	maxX := min(area.X+area.W, mat.Cols())
+	maxY := min(area.Y+area.H, mat.Rows())
+	if maxX-minX < template.Cols() || maxY-minY < template.Rows() {
+		return false
+	}
+
+	roi := mat.Region(image.Rect(minX, minY, maxX, maxY))
+	defer roi.Close()
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(roi, &gray, gocv.ColorBGRToGray)
+
+	result := gocv.NewMat()
+	defer result.Close()
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.MatchTemplate(gray, *template, &result, gocv.TmCcoeffNormed, mask)
+
+	_, maxVal, _, _ := gocv.MinMaxLoc(result)
+	return maxVal >= messageThreshold
+}
+
+// replaceTemplate closes current and loads path as grayscale template.
+// Returns nil if path is "" or cannot be loaded.
+func replaceTemplate(current *gocv.Mat, path, name string) *gocv.Mat {
+	if current != nil {
+		current.Close()
+	}
+	if path == "" {
+		return nil
+	}
+
+	template := gocv.IMRead(path, gocv.IMReadGrayScale)
+	if template.Empty() {
+		LogWarn("Failed to load %s %s", name, path)
+		return nil
+	}
+	return &template
+}
+
+// MatchTemplates matches the nameplate templates in dir, (re)loading them
+// when dir changes
+func (d *cvDetector) MatchTemplates(img *image.RGBA, region Bounds, dir string, threshold float32) ([]Target, int) {
+	d.mu.Lock()
+	if d.templates == nil || d.templates.dir != dir {
+		if d.templates != nil {
+			d.templates.Close()
+		}
+		d.templates = NewTemplateMatcher(dir)
+	}
+	matcher := d.templates
+	d.mu.Unlock()
+
+	return matcher.Match(img, region, threshold), matcher.Count()
+}
+
+// inventoryFullMinPixels is the minimum number of red text pixels in the
+// message band before it is read with OCR
+const inventoryFullMinPixels = 80
+
+// inventoryFullOCRInterval is how long an OCR read of the message band is
+// reused, so tesseract does not run on every frame while red text is shown
+const inventoryFullOCRInterval = time.Second
+
+// inventoryFullBand is the system message band searched for the "inventory
+// full" message (800x600 base resolution)
+var inventoryFullBand = Bounds{X: 200, Y: 180, W: 400, H: 60}
+
+// detectInventoryFull checks the system message band near the screen center
+// for the "inventory full" text. Red text only triggers the OCR read, which
+// must find one of the MessageInventoryFull phrases. Called with d.mu held.
+func (d *cvDetector) detectInventoryFull(img *image.RGBA, hsvMat *gocv.Mat) bool {
+	// Message band: (200,180)-(600,240) on the 800x600 base resolution
+	minX, minY := d.screenInfo.Scale(inventoryFullBand.X, inventoryFullBand.Y)
+	maxX, maxY := d.screenInfo.Scale(inventoryFullBand.X+inventoryFullBand.W, inventoryFullBand.Y+inventoryFullBand.H)
+	maxX = min(maxX, hsvMat.Cols())
+	maxY = min(maxY, hsvMat.Rows())
+	if minX >= maxX || minY >= maxY {
+		return false
+	}
+
+	roi := hsvMat.Region(image.Rect(minX, minY, maxX, maxY))
+	defer roi.Close()
+
+	// Red text (hue wraps around 0)
+	lowMask := gocv.NewMat()
+	defer lowMask.Close()
+	gocv.InRangeWithScalar(roi, gocv.NewScalar(0, 150, 150, 0), gocv.NewScalar(10, 255, 255, 0), &lowMask)
+
+	highMask := gocv.NewMat()
+	defer highMask.Close()
+	gocv.InRangeWithScalar(roi, gocv.NewScalar(170, 150, 150, 0), gocv.NewScalar(180, 255, 255, 0), &highMask)
+
+	if gocv.CountNonZero(lowMask)+gocv.CountNonZero(highMask) < inventoryFullMinPixels {
+		return false
+	}
+
+	if time.Since(d.fullReadAt) < inventoryFullOCRInterval {
+		return d.fullRead
+	}
+
+	text, err := RecognizeText(img, Bounds{X: minX, Y: minY, W: maxX - minX, H: maxY - minY})
+	d.fullReadAt = time.Now()
+	if err != nil {
+		LogDebug("Inventory full message OCR failed: %v", err)
+		d.fullRead = false
+		return false
+	}
+
+	d.fullRead = gameMessages.ContainsText(text, MessageInventoryFull)
+	if d.fullRead {
+		LogDebug("Inventory full message read: %q", text)
+	}
+	return d.fullRead
+}
+
+// Popup detection: minimum frame size (800x600 base resolution), the share of
+// the bounding box that must be panel-colored and the share of the window
+// border that must be dark frame
+const (
+	popupMinWidth  = 150
+	popupMinHeight = 100
+	popupMinFill   = 0.5
+	popupMinFrame  = 0.6
+	popupFrameSize = 3 // Thickness of the border strips checked around the panel
+)
+
+// detectPopup checks the screen center for a game window (popup): a large,
+// mostly uniform area of the beige window panel color enclosed by the dark
+// window frame. The frame keeps beige ground, walls and sky out.
+func (d *cvDetector) detectPopup(hsvMat *gocv.Mat) bool {
+	// Popups open around the center: (150,100)-(650,500) on the 800x600 base resolution
+	minX, minY := d.screenInfo.Scale(150, 100)
+	maxX, maxY := d.screenInfo.Scale(650, 500)
+	maxX = min(maxX, hsvMat.Cols())
+	maxY = min(maxY, hsvMat.Rows())
+	if minX >= maxX || minY >= maxY {
+		return false
+	}
+
+	roi := hsvMat.Region(image.Rect(minX, minY, maxX, maxY))
+	defer roi.Close()
+
+	// Beige window panel
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.InRangeWithScalar(roi, gocv.NewScalar(15, 20, 190, 0), gocv.NewScalar(30, 90, 255, 0), &mask)
+
+	// Dark window frame
+	frameMask := gocv.NewMat()
+	defer frameMask.Close()
+	gocv.InRangeWithScalar(roi, gocv.NewScalar(0, 0, 0, 0), gocv.NewScalar(180, 255, 110, 0), &frameMask)
+
+	contours := gocv.FindContours(mask, gocv.RetrievalExternal, gocv.ChainApproxSimple)
+	defer contours.Close()
+
+	minW, minH := d.screenInfo.Scale(popupMinWidth, popupMinHeight)
+	for i := 0; i < contours.Size(); i++ {
+		rect := gocv.BoundingRect(contours.At(i))
+		if rect.Dx() < minW || rect.Dy() < minH {
+			continue
+		}
+
+		panel := mask.Region(rect)
+		fill := float64(gocv.CountNonZero(panel)) / float64(rect.Dx()*rect.Dy())
+		panel.Close()
+		if fill < popupMinFill {
+			continue
+		}
+
+		frame := popupFrameShare(&frameMask, rect)
+		if frame >= popupMinFrame {
+			LogDebug("Popup detected at (%d,%d) size %dx%d (fill %.2f, frame %.2f)", minX+rect.Min.X, minY+rect.Min.Y, rect.Dx(), rect.Dy(), fill, frame)
+			return true
+		}
+	}
+	return false
+}
+
+// popupFrameShare returns the share of frame-colored pixels in the strips
+// just outside the four sides of rect (clipped to the mask)
+func popupFrameShare(frameMask *gocv.Mat, rect image.Rectangle) float64 {
+	bounds := image.Rect(0, 0, frameMask.Cols(), frameMask.Rows())
+	sides := []image.Rectangle{
+		image.Rect(rect.Min.X, rect.Min.Y-popupFrameSize, rect.Max.X, rect.Min.Y), // Top
+		image.Rect(rect.Min.X, rect.Max.Y, rect.Max.X, rect.Max.Y+popupFrameSize), // Bottom
+		image.Rect(rect.Min.X-popupFrameSize, rect.Min.Y, rect.Min.X, rect.Max.Y), // Left
+		image.Rect(rect.Max.X, rect.Min.Y, rect.Max.X+popupFrameSize, rect.Max.Y), // Right
+	}
+
+	framed, total := 0, 0
+	for _, side := range sides {
+		side = side.Intersect(bounds)
+		if side.Empty() {
+			continue
+		}
+		strip := frameMask.Region(side)
+		framed += gocv.CountNonZero(strip)
+		strip.Close()
+		total += side.Dx() * side.Dy()
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(framed) / float64(total)
+}
+
+// Cast bar detection: minimum size of the progress fill (800x600 base resolution)
+// and the maximum bar height
+const (
+	castBarMinWidth  = 20
+	castBarMinHeight = 3
+	castBarMaxHeight = 12
+)
+
+// detectCastBar checks below the character for the progress bar shown while a
+// skill is casting: a thin, wide strip of saturated yellow fill
+func (d *cvDetector) detectCastBar(hsvMat *gocv.Mat) bool {
+	// Cast bar: (300,370)-(500,420) on the 800x600 base resolution
+	minX, minY := d.screenInfo.Scale(300, 370)
+	maxX, maxY := d.screenInfo.Scale(500, 420)
+	maxX = min(maxX, hsvMat.Cols())
+	maxY = min(maxY, hsvMat.Rows())
+	if minX >= maxX || minY >= maxY {
+		return false
+	}
+
+	roi := hsvMat.Region(image.Rect(minX, minY, maxX, maxY))
+	defer roi.Close()
+
+	// Yellow progress fill
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.InRangeWithScalar(roi, gocv.NewScalar(18, 140, 180, 0), gocv.NewScalar(35, 255, 255, 0), &mask)
+
+	contours := gocv.FindContours(mask, gocv.RetrievalExternal, gocv.ChainApproxSimple)
+	defer contours.Close()
+
+	minW, minH := d.screenInfo.Scale(castBarMinWidth, castBarMinHeight)
+	_, maxH := d.screenInfo.Scale(0, castBarMaxHeight)
+	for i := 0; i < contours.Size(); i++ {
+		rect := gocv.BoundingRect(contours.At(i))
+		// The fill grows from the left, so only its height and a minimum width are fixed
+		if rect.Dx() >= minW && rect.Dy() >= minH && rect.Dy() <= maxH && rect.Dx() > rect.Dy()*3 {
+			LogDebug("Cast bar detected at (%d,%d) width %d", minX+rect.Min.X, minY+rect.Min.Y, rect.Dx())
+			return true
+		}
+	}
+	return false
+}
+
+// Chat input detection: size of the focused input box frame (800x600 base resolution)
+const (
+	chatInputMinWidth  = 150
+	chatInputMinHeight = 12
+	chatInputMaxHeight = 30
+)
+
+// detectChatInput checks the bottom left corner for the chat input box, which
+// only appears while the chat is focused: a wide, short frame of light grey
+// around a dark field
+func (d *cvDetector) detectChatInput(hsvMat *gocv.Mat) bool {
+	// Chat input: (0,520)-(400,600) on the 800x600 base resolution
+	minX, minY := d.screenInfo.Scale(0, 520)
+	maxX, maxY := d.screenInfo.Scale(400, 600)
+	maxX = min(maxX, hsvMat.Cols())
+	maxY = min(maxY, hsvMat.Rows())
+	if minX >= maxX || minY >= maxY {
+		return false
+	}
+
+	roi := hsvMat.Region(image.Rect(minX, minY, maxX, maxY))
+	defer roi.Close()
+
+	// Light, unsaturated frame
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.InRangeWithScalar(roi, gocv.NewScalar(0, 0, 170, 0), gocv.NewScalar(180, 50, 255, 0), &mask)
+
+	contours := gocv.FindContours(mask, gocv.RetrievalExternal, gocv.ChainApproxSimple)
+	defer contours.Close()
+
+	minW, minH := d.screenInfo.Scale(chatInputMinWidth, chatInputMinHeight)
+	_, maxH := d.screenInfo.Scale(0, chatInputMaxHeight)
+	for i := 0; i < contours.Size(); i++ {
+		rect := gocv.BoundingRect(contours.At(i))
+		if rect.Dx() < minW || rect.Dy() < minH || rect.Dy() > maxH {
+			continue
+		}
+		// A frame, not a filled box (chat text, bright ground): the light
+		// pixels cover little of the bounding rect
+		frame := mask.Region(rect)
+		filled := gocv.CountNonZero(frame)
+		frame.Close()
+		if filled > rect.Dx()*rect.Dy()/2 {
+			continue
+		}
+		LogDebug("Chat input detected at (%d,%d) %dx%d", minX+rect.Min.X, minY+rect.Min.Y, rect.Dx(), rect.Dy())
+		return true
+	}
+	return false
+}
+
+// petIconThreshold is the minimum normalized correlation for the pet icon
+const petIconThreshold = 0.8
+
+// detectPickupPet checks the buff bar along the top of the screen for the
+// pickup pet icon. Must be called with d.mu held.
+func (d *cvDetector) detectPickupPet(mat *gocv.Mat) bool {
+	// Buff bar: (0,0)-(800,100) on the 800x600 base resolution
+	maxX, maxY := d.screenInfo.Scale(800, 100)
+	maxX = min(maxX, mat.Cols())
+	maxY = min(maxY, mat.Rows())
+	if maxX < d.petIcon.Cols() || maxY < d.petIcon.Rows() {
+		return false
+	}
+
+	roi := mat.Region(image.Rect(0, 0, maxX, maxY))
+	defer roi.Close()
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(roi, &gray, gocv.ColorBGRToGray)
+
+	result := gocv.NewMat()
+	defer result.Close()
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.MatchTemplate(gray, *d.petIcon, &result, gocv.TmCcoeffNormed, mask)
+
+	_, maxVal, _, maxLoc := gocv.MinMaxLoc(result)
+	if maxVal < petIconThreshold {
+		return false
+	}
+	LogDebug("Pickup pet icon detected at (%d,%d) (score %.2f)", maxLoc.X, maxLoc.Y, maxVal)
+	return true
+}
+
+// debuffIconThreshold is the minimum normalized correlation for a debuff icon
+const debuffIconThreshold = 0.8
+
+// detectDebuffs looks for the debuff icons on the buff bar. Returns the name of
+// the first one found, "" if none. Must be called with d.mu held.
+func (d *cvDetector) detectDebuffs(mat *gocv.Mat) string {
+	// Buff bar: (0,0)-(800,100) on the 800x600 base resolution
+	maxX, maxY := d.screenInfo.Scale(800, 100)
+	maxX = min(maxX, mat.Cols())
+	maxY = min(maxY, mat.Rows())
+
+	roi := mat.Region(image.Rect(0, 0, maxX, maxY))
+	defer roi.Close()
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(roi, &gray, gocv.ColorBGRToGray)
+
+	for name, icon := range d.debuffs {
+		if maxX < icon.Cols() || maxY < icon.Rows() {
+			continue
+		}
+
+		result := gocv.NewMat()
+		mask := gocv.NewMat()
+		gocv.MatchTemplate(gray, *icon, &result, gocv.TmCcoeffNormed, mask)
+		_, maxVal, _, maxLoc := gocv.MinMaxLoc(result)
+		result.Close()
+		mask.Close()
+
+		if maxVal >= debuffIconThreshold {
+			LogDebug("Debuff icon %s detected at (%d,%d) (score %.2f)", name, maxLoc.X, maxLoc.Y, maxVal)
+			return name
+		}
+	}
+	return ""
+}
+
+// partyInviteThreshold is the minimum normalized correlation for the party invite dialog
+const partyInviteThreshold = 0.8
+
+// detectPartyInvite looks for the party invite dialog around the screen center.
+// Returns the dialog bounds, nil if it is not open. Must be called with d.mu held.
+func (d *cvDetector) detectPartyInvite(mat *gocv.Mat) *Bounds {
+	// Dialog area: (100,100)-(700,500) on the 800x600 base resolution
+	minX, minY := d.screenInfo.Scale(100, 100)
+	maxX, maxY := d.screenInfo.Scale(700, 500)
+	maxX = min(maxX, mat.Cols())
+	maxY = min(maxY, mat.Rows())
+	if maxX-minX < d.inviteDlg.Cols() || maxY-minY < d.inviteDlg.Rows() {
+		return nil
+	}
+
+	roi := mat.Region(image.Rect(minX, minY, maxX, maxY))
+	defer roi.Close()
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(roi, &gray, gocv.ColorBGRToGray)
+
+	result := gocv.NewMat()
+	defer result.Close()
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.MatchTemplate(gray, *d.inviteDlg, &result, gocv.TmCcoeffNormed, mask)
+
+	_, maxVal, _, maxLoc := gocv.MinMaxLoc(result)
+	if maxVal < partyInviteThreshold {
+		return nil
+	}
+	dialog := NewBounds(minX+maxLoc.X, minY+maxLoc.Y, d.inviteDlg.Cols(), d.inviteDlg.Rows())
+	LogDebug("Party invite dialog detected at (%d,%d) (score %.2f)", dialog.X, dialog.Y, maxVal)
+	return &dialog
+}
+
+// actionBarCoolingRatio is the minimum share of red sweep pixels in an action
+// bar icon for its slot to count as cooling down
+const actionBarCoolingRatio = 0.25
+
+// detectActionBarCooldowns splits the action bar into its 10 icons (slots 1-9
+// then 0) and reports the slots whose icon is covered by the red cooldown
+// sweep. Must be called with d.mu held.
+func (d *cvDetector) detectActionBarCooldowns(hsvMat *gocv.Mat) [10]bool {
+	var cooling [10]bool
+
+	minX, minY := d.screenInfo.Scale(d.actionBar.X, d.actionBar.Y)
+	maxX, maxY := d.screenInfo.Scale(d.actionBar.X+d.actionBar.W, d.actionBar.Y+d.actionBar.H)
+	maxX = min(maxX, hsvMat.Cols())
+	maxY = min(maxY, hsvMat.Rows())
+	if maxX-minX < len(cooling) || minY >= maxY {
+		return cooling
+	}
+
+	roi := hsvMat.Region(image.Rect(minX, minY, maxX, maxY))
+	defer roi.Close()
+
+	// Red sweep: hue wraps around 0
+	lowRed := gocv.NewMat()
+	defer lowRed.Close()
+	gocv.InRangeWithScalar(roi, gocv.NewScalar(0, 100, 60, 0), gocv.NewScalar(10, 255, 255, 0), &lowRed)
+	highRed := gocv.NewMat()
+	defer highRed.Close()
+	gocv.InRangeWithScalar(roi, gocv.NewScalar(170, 100, 60, 0), gocv.NewScalar(180, 255, 255, 0), &highRed)
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.BitwiseOr(lowRed, highRed, &mask)
+
+	width := maxX - minX
+	height := maxY - minY
+	for i := range cooling {
+		icon := mask.Region(image.Rect(i*width/len(cooling), 0, (i+1)*width/len(cooling), height))
+		red := gocv.CountNonZero(icon)
+		area := icon.Cols() * icon.Rows()
+		icon.Close()
+
+		// Icon i is bound to key i+1, the last one to key 0
+		slot := (i + 1) % len(cooling)
+		cooling[slot] = area > 0 && float64(red)/float64(area) >= actionBarCoolingRatio
+	}
+	return cooling
+}
+
+// imageToMat converts image.RGBA to gocv.Mat (BGR format for OpenCV)
+func imageToMat(img *image.RGBA) gocv.Mat {
+	if img == nil {
+		return gocv.NewMat()
+	}
+
+	// Convert RGBA to BGR for OpenCV
+	mat, err := gocv.ImageToMatRGB(img)
+	if err != nil {
+		LogError("Failed to convert image to mat: %v", err)
+		return gocv.NewMat()
+	}
+
+	return mat
+}
@@ -0,0 +1,88 @@
+//go:build !nocv
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// loadFrame reads a captured game frame as RGBA
+func loadFrame(t *testing.T, path string) *image.RGBA {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", path, err)
+	}
+	frame := image.NewRGBA(img.Bounds())
+	draw.Draw(frame, frame.Bounds(), img, img.Bounds().Min, draw.Src)
+	return frame
+}
+
+// drawChatInput draws the focused chat input box into frame: a light grey
+// frame around a dark field in the bottom left corner (800x600 base resolution)
+func drawChatInput(frame *image.RGBA, screenInfo *ScreenInfo) {
+	minX, minY := screenInfo.Scale(10, 560)
+	maxX, maxY := screenInfo.Scale(330, 580)
+	border := max(2, (maxY-minY)/8)
+
+	draw.Draw(frame, image.Rect(minX, minY, maxX, maxY), image.NewUniform(color.RGBA{200, 200, 205, 255}), image.Point{}, draw.Src)
+	draw.Draw(frame, image.Rect(minX+border, minY+border, maxX-border, maxY-border), image.NewUniform(color.RGBA{20, 20, 25, 255}), image.Point{}, draw.Src)
+}
+
+// hsvFrame converts frame to the HSV mat UpdateStats passes to the detections
+func hsvFrame(t *testing.T, frame *image.RGBA) gocv.Mat {
+	t.Helper()
+
+	mat := imageToMat(frame)
+	defer mat.Close()
+	if mat.Empty() {
+		t.Fatal("failed to convert frame")
+	}
+
+	hsvMat := gocv.NewMat()
+	gocv.CvtColor(mat, &hsvMat, gocv.ColorBGRToHSV)
+	return hsvMat
+}
+
+func TestDetectChatInput(t *testing.T) {
+	closed := loadFrame(t, "train.png")
+	screenInfo := NewScreenInfo(closed.Bounds())
+	d := newDetector(screenInfo).(*cvDetector)
+
+	open := image.NewRGBA(closed.Bounds())
+	draw.Draw(open, open.Bounds(), closed, closed.Bounds().Min, draw.Src)
+	drawChatInput(open, screenInfo)
+
+	tests := []struct {
+		name  string
+		frame *image.RGBA
+		want  bool
+	}{
+		{"chat closed", closed, false},
+		{"chat open", open, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hsvMat := hsvFrame(t, tt.frame)
+			defer hsvMat.Close()
+
+			if got := d.detectChatInput(&hsvMat); got != tt.want {
+				t.Errorf("detectChatInput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+//go:build nocv
+
+// Package main - detector_stub.go
+//
+// This file implements the Detector for builds without OpenCV (-tags nocv).
+// Every detection is unavailable and returns empty results, see detector.go.
+package main
+
+import (
+	"fmt"
+	"image"
+	"sync"
+)
+
+// stubDetector is the Detector of builds without OpenCV
+type stubDetector struct {
+	warnOnce sync.Once
+}
+
+// newDetector creates the stub detector
+func newDetector(screenInfo *ScreenInfo) Detector {
+	return &stubDetector{}
+}
+
+// warn logs once that detection is unavailable
+func (d *stubDetector) warn() {
+	d.warnOnce.Do(func() {
+		LogWarn("Built without OpenCV (-tags nocv): detection unavailable")
+	})
+}
+
+// Available always reports false
+func (d *stubDetector) Available() bool {
+	return false
+}
+
+// UpdateStats leaves stats untouched
+func (d *stubDetector) UpdateStats(img *image.RGBA, stats *ClientStats, full bool) bool {
+	d.warn()
+	return true
+}
+
+// CalibrateBars always fails
+func (d *stubDetector) CalibrateBars(img *image.RGBA, stats *ClientStats) (map[string]HSVRange, error) {
+	d.warn()
+	return nil, fmt.Errorf("detection unavailable (built without OpenCV)")
+}
+
+// SetPickupPetIcon ignores the icon
+func (d *stubDetector) SetPickupPetIcon(path string) {
+	if path != "" {
+		d.warn()
+	}
+}
+
+// SetPartyInviteTemplate ignores the template
+func (d *stubDetector) SetPartyInviteTemplate(path string) {
+	if path != "" {
+		d.warn()
+	}
+}
+
+// SetActionBar ignores the action bar
+func (d *stubDetector) SetActionBar(area *Bounds) {
+	if area != nil {
+		d.warn()
+	}
+}
+
+// SetDebuffIcons ignores the icons
+func (d *stubDetector) SetDebuffIcons(icons map[string]string) {
+	if len(icons) > 0 {
+		d.warn()
+	}
+}
+
+// SetMessageTemplate ignores the template
+func (d *stubDetector) SetMessageTemplate(event, path string) {
+	if path != "" {
+		d.warn()
+	}
+}
+
+// MatchMessage has no templates
+func (d *stubDetector) MatchMessage(img *image.RGBA, region Bounds, event string) (bool, bool) {
+	return false, false
+}
+
+// MatchTemplates finds nothing
+func (d *stubDetector) MatchTemplates(img *image.RGBA, region Bounds, dir string, threshold float32) ([]Target, int) {
+	d.warn()
+	return nil, 0
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import "image"
+
+// rectOnDisplay reports whether any part of r is on a connected display.
+// Display bounds are unknown on this platform (second result false).
+func rectOnDisplay(r image.Rectangle) (bool, bool) {
+	return false, false
+}
+
+// primaryDisplayBounds returns the primary display area, unknown on this platform
+func primaryDisplayBounds() (image.Rectangle, bool) {
+	return image.Rectangle{}, false
+}
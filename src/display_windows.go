@@ -0,0 +1,41 @@
+//go:build windows
+
+package main
+
+import (
+	"image"
+	"unsafe"
+)
+
+const (
+	smCxScreen           = 0
+	smCyScreen           = 1
+	monitorDefaultToNull = 0
+)
+
+var (
+	procGetSystemMetrics = user32.NewProc("GetSystemMetrics")
+	procMonitorFromRect  = user32.NewProc("MonitorFromRect")
+)
+
+// winRect mirrors the Win32 RECT structure
+type winRect struct {
+	Left, Top, Right, Bottom int32
+}
+
+// rectOnDisplay reports whether any part of r is on a connected display
+func rectOnDisplay(r image.Rectangle) (bool, bool) {
+	rect := winRect{Left: int32(r.Min.X), Top: int32(r.Min.Y), Right: int32(r.Max.X), Bottom: int32(r.Max.Y)}
+	monitor, _, _ := procMonitorFromRect.Call(uintptr(unsafe.Pointer(&rect)), monitorDefaultToNull)
+	return monitor != 0, true
+}
+
+// primaryDisplayBounds returns the primary display area (always at the origin)
+func primaryDisplayBounds() (image.Rectangle, bool) {
+	width, _, _ := procGetSystemMetrics.Call(smCxScreen)
+	height, _, _ := procGetSystemMetrics.Call(smCyScreen)
+	if width == 0 || height == 0 {
+		return image.Rectangle{}, false
+	}
+	return image.Rect(0, 0, int(width), int(height)), true
+}
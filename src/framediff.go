@@ -0,0 +1,109 @@
+// Package main - framediff.go
+//
+// This file detects static frames: frames nearly identical to the last
+// processed frame, for which ImageAnalyzer reuses its previous status bar and
+// mob results instead of detecting again (Config.SkipStaticFrames).
+//
+// Frames are compared on a downscaled luminance grid. Grid cells covering a
+// status bar area are checked one by one, so a small HP/MP/FP change is never
+// averaged away by the rest of the screen; the other cells are compared by
+// their mean difference.
+package main
+
+import "image"
+
+// Size of the luminance grid frames are compared on
+const (
+	staticGridW = 64
+	staticGridH = 48
+)
+
+// staticMaxSkips is the maximum number of consecutive static frames, a frame is
+// processed after that even if nothing seems to have changed
+const staticMaxSkips = 10
+
+// frameSignature is a downscaled luminance grid of a frame
+type frameSignature struct {
+	width  int     // Frame width
+	height int     // Frame height
+	cells  []uint8 // Mean luminance per cell, row by row
+	bar    []bool  // Cell overlaps a status bar area
+}
+
+// newFrameSignature computes the luminance grid of img. Cells overlapping one
+// of barAreas are marked as status bar cells.
+func newFrameSignature(img *image.RGBA, barAreas []image.Rectangle) *frameSignature {
+	bounds := img.Bounds()
+	sig := &frameSignature{
+		width:  bounds.Dx(),
+		height: bounds.Dy(),
+		cells:  make([]uint8, staticGridW*staticGridH),
+		bar:    make([]bool, staticGridW*staticGridH),
+	}
+	if sig.width < staticGridW || sig.height < staticGridH {
+		return sig
+	}
+
+	for cy := 0; cy < staticGridH; cy++ {
+		for cx := 0; cx < staticGridW; cx++ {
+			cell := image.Rect(
+				bounds.Min.X+cx*sig.width/staticGridW, bounds.Min.Y+cy*sig.height/staticGridH,
+				bounds.Min.X+(cx+1)*sig.width/staticGridW, bounds.Min.Y+(cy+1)*sig.height/staticGridH,
+			)
+
+			sum, count := 0, 0
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				offset := img.PixOffset(cell.Min.X, y)
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					r, g, b := int(img.Pix[offset]), int(img.Pix[offset+1]), int(img.Pix[offset+2])
+					sum += (r*299 + g*587 + b*114) / 1000
+					count++
+					offset += 4
+				}
+			}
+
+			i := cy*staticGridW + cx
+			sig.cells[i] = uint8(sum / max(count, 1))
+			for _, area := range barAreas {
+				if cell.Overlaps(area) {
+					sig.bar[i] = true
+					break
+				}
+			}
+		}
+	}
+	return sig
+}
+
+// isStatic reports whether the frame matches prev: every status bar cell and
+// the mean of the other cells differ by less than threshold (luminance 0-255)
+func (sig *frameSignature) isStatic(prev *frameSignature, threshold float64) bool {
+	if prev == nil || sig.width != prev.width || sig.height != prev.height {
+		return false
+	}
+
+	sum, count := 0, 0
+	for i, value := range sig.cells {
+		diff := abs(int(value) - int(prev.cells[i]))
+		if sig.bar[i] {
+			if float64(diff) >= threshold {
+				return false
+			}
+			continue
+		}
+		sum += diff
+		count++
+	}
+	return count == 0 || float64(sum)/float64(count) < threshold
+}
+
+// statusBarAreas returns the areas searched for the player and target bars
+func statusBarAreas() []image.Rectangle {
+	kinds := []StatusBarKind{StatusBarHP, StatusBarMP, StatusBarFP, StatusBarTargetHP, StatusBarTargetMP}
+	areas := make([]image.Rectangle, 0, len(kinds))
+	for _, kind := range kinds {
+		config := GetStatusBarConfig(kind)
+		areas = append(areas, image.Rect(config.MinX, config.MinY, config.MaxX, config.MaxY))
+	}
+	return areas
+}
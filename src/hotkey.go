@@ -0,0 +1,116 @@
+// Package main - hotkey.go
+//
+// Global pause/resume hotkey.
+// The OS-specific listener lives in hotkey_windows.go (RegisterHotKey) and
+// hotkey_other.go (unsupported stub). Presses never switch modes directly:
+// they queue a mode request that the main loop applies between iterations.
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hotkey is a parsed key combination such as "Ctrl+Shift+P"
+type Hotkey struct {
+	Ctrl  bool
+	Alt   bool
+	Shift bool
+	Win   bool
+	Key   string // Upper-case key name ("P", "F10", "PAUSE", ...)
+}
+
+// ParseHotkey parses a "+"-separated key combination (case-insensitive)
+func ParseHotkey(combo string) (Hotkey, error) {
+	var hotkey Hotkey
+	for _, part := range strings.Split(combo, "+") {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		switch part {
+		case "":
+			return Hotkey{}, fmt.Errorf("empty key in hotkey %q", combo)
+		case "CTRL", "CONTROL":
+			hotkey.Ctrl = true
+		case "ALT":
+			hotkey.Alt = true
+		case "SHIFT":
+			hotkey.Shift = true
+		case "WIN", "SUPER", "CMD":
+			hotkey.Win = true
+		default:
+			if hotkey.Key != "" {
+				return Hotkey{}, fmt.Errorf("hotkey %q has more than one key", combo)
+			}
+			hotkey.Key = part
+		}
+	}
+
+	if hotkey.Key == "" {
+		return Hotkey{}, fmt.Errorf("hotkey %q has no key", combo)
+	}
+	return hotkey, nil
+}
+
+// String returns the hotkey in "Ctrl+Alt+Shift+Win+Key" form
+func (h Hotkey) String() string {
+	var parts []string
+	if h.Ctrl {
+		parts = append(parts, "Ctrl")
+	}
+	if h.Alt {
+		parts = append(parts, "Alt")
+	}
+	if h.Shift {
+		parts = append(parts, "Shift")
+	}
+	if h.Win {
+		parts = append(parts, "Win")
+	}
+	return strings.Join(append(parts, h.Key), "+")
+}
+
+// startPauseHotkey registers config.PauseHotkey, if set, to toggle TogglePause
+func (b *Bot) startPauseHotkey() {
+	b.config.mu.RLock()
+	combo := b.config.PauseHotkey
+	b.config.mu.RUnlock()
+
+	if combo == "" {
+		return
+	}
+
+	hotkey, err := ParseHotkey(combo)
+	if err != nil {
+		LogWarn("Invalid pause hotkey: %v", err)
+		return
+	}
+
+	if err := listenHotkey(hotkey, b.TogglePause); err != nil {
+		LogWarn("Failed to register pause hotkey %s: %v", hotkey, err)
+		return
+	}
+	LogInfo("Pause hotkey registered: %s", hotkey)
+}
+
+// TogglePause switches between the current mode and Stop.
+// The mode change is queued for the main loop so it never runs concurrently
+// with a behavior iteration.
+func (b *Bot) TogglePause() {
+	b.pauseMu.Lock()
+	target := "Stop"
+	if current := b.config.GetMode(); current == "Stop" {
+		target = b.pausedMode
+		if target == "" {
+			target = "Farming"
+		}
+	} else {
+		b.pausedMode = current
+	}
+	b.pauseMu.Unlock()
+
+	select {
+	case b.modeRequests <- target:
+		LogInfo("Pause hotkey pressed, switching to %s", target)
+	default:
+		LogDebug("Pause hotkey pressed, mode change already pending")
+	}
+}
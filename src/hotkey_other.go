@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "fmt"
+
+// listenHotkey is not supported on this platform
+func listenHotkey(hotkey Hotkey, onPress func()) error {
+	return fmt.Errorf("global hotkeys are not supported on this platform")
+}
@@ -0,0 +1,114 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	modAlt      = 0x0001
+	modControl  = 0x0002
+	modShift    = 0x0004
+	modWin      = 0x0008
+	modNoRepeat = 0x4000
+	wmHotkey    = 0x0312
+
+	pauseHotkeyID = 1
+)
+
+var (
+	user32             = windows.NewLazySystemDLL("user32.dll")
+	procRegisterHotKey = user32.NewProc("RegisterHotKey")
+	procGetMessageW    = user32.NewProc("GetMessageW")
+)
+
+// winMsg mirrors the Win32 MSG structure
+type winMsg struct {
+	HWnd    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	PtX     int32
+	PtY     int32
+}
+
+// namedVirtualKeys maps key names to Win32 virtual key codes
+var namedVirtualKeys = map[string]uint32{
+	"PAUSE":  0x13,
+	"SPACE":  0x20,
+	"END":    0x23,
+	"HOME":   0x24,
+	"INSERT": 0x2D,
+	"DELETE": 0x2E,
+	"SCROLL": 0x91,
+}
+
+// virtualKeyCode returns the Win32 virtual key code of a key name
+func virtualKeyCode(key string) (uint32, bool) {
+	if len(key) == 1 && (key[0] >= 'A' && key[0] <= 'Z' || key[0] >= '0' && key[0] <= '9') {
+		return uint32(key[0]), true
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(key, "F")); err == nil && strings.HasPrefix(key, "F") && n >= 1 && n <= 24 {
+		return uint32(0x70 + n - 1), true
+	}
+	code, ok := namedVirtualKeys[key]
+	return code, ok
+}
+
+// listenHotkey registers a system-wide hotkey and calls onPress on every press.
+// The hotkey belongs to the registering thread, so registration and the message
+// loop run on one locked OS thread.
+func listenHotkey(hotkey Hotkey, onPress func()) error {
+	vk, ok := virtualKeyCode(hotkey.Key)
+	if !ok {
+		return fmt.Errorf("unsupported key %q", hotkey.Key)
+	}
+
+	mods := uint32(modNoRepeat)
+	if hotkey.Ctrl {
+		mods |= modControl
+	}
+	if hotkey.Alt {
+		mods |= modAlt
+	}
+	if hotkey.Shift {
+		mods |= modShift
+	}
+	if hotkey.Win {
+		mods |= modWin
+	}
+
+	errCh := make(chan error, 1)
+	SafeGo(func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		r, _, err := procRegisterHotKey.Call(0, pauseHotkeyID, uintptr(mods), uintptr(vk))
+		if r == 0 {
+			errCh <- fmt.Errorf("RegisterHotKey: %w", err)
+			return
+		}
+		errCh <- nil
+
+		var msg winMsg
+		for {
+			r, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+			if int32(r) <= 0 {
+				return
+			}
+			if msg.Message == wmHotkey && msg.WParam == pauseHotkeyID {
+				onPress()
+			}
+		}
+	})
+
+	return <-errCh
+}
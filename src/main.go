@@ -41,8 +41,10 @@ package main
 
 import (
 	"fmt"
+	"image"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -56,10 +58,24 @@ import (
 //   - Run: Execute one iteration of the behavior logic
 //   - Stop: Gracefully terminate and clean up behavior state
 //   - GetState: Return the current state name for debug overlay
+//   - PreferredInterval: Capture interval in ms for this behavior, 0 = use Config.CaptureInterval
 type BotBehavior interface {
 	Run(analyzer *ImageAnalyzer, movement *MovementCoordinator, config *Config, stats *Statistics) error
 	Stop()
 	GetState() string
+	PreferredInterval(config *Config) int
+}
+
+// AdaptiveCaptureBehavior is implemented by behaviors that choose the capture
+// interval for their current state (used when Config.AdaptiveCapture is enabled)
+type AdaptiveCaptureBehavior interface {
+	CaptureInterval(config *Config) int // Interval in ms, -1 = use Config.CaptureInterval
+}
+
+// AntiIdleBehavior is implemented by behaviors that restrict when anti-idle
+// inputs (Config.AntiIdleMs) may be sent, e.g. not in the middle of a fight
+type AntiIdleBehavior interface {
+	AntiIdleAllowed() bool
 }
 
 // Bot represents the main bot controller and orchestrates all subsystems.
@@ -97,13 +113,38 @@ type Bot struct {
 	movement     *MovementCoordinator
 	behavior     BotBehavior
 	tray         *TrayApp
+	api          *APIServer // HTTP status/control API, nil if disabled
 	stopChan     chan bool
 	data         *PersistentData
 	cookiesSaved bool // Flag to track if cookies have been saved after game loads
+	lastChatLine string // Last chat line handled by checkChatMessages
+
+	// Mode changes from the tray, HTTP API and pause hotkey are queued and applied by the main loop
+	modeRequests chan string
+	slotTests    chan int // Tray "Test Slot" requests, run by the main loop
+	pauseMu      sync.Mutex
+	pausedMode   string // Mode to resume after a hotkey pause
+
+	// Behavior state published by the main loop for other goroutines (HTTP API)
+	statusMu     sync.Mutex
+	statusStage  string // GetState of the behavior ("Idle" when stopped)
+	statusHalted string // FarmingBehavior.HaltReason ("" = not halted)
+
+	// Session limits (Config.MaxKills, Config.MaxRuntimeMinutes), counted from the last completed session
+	sessionStart    time.Time
+	sessionKills    int    // Statistics kill count when the session started
+	sessionComplete string // Why the last session completed, shown in the tray until another mode starts
 
 	// Async debug overlay rendering
 	debugOverlayChan chan *DebugOverlayRequest
 	debugOverlayEnabled bool
+
+	// Async timelapse snapshots (Config.SnapshotIntervalSec)
+	snapshotChan chan *image.RGBA
+	lastSnapshot time.Time
+
+	// Power saving (Config.PauseWhenBackgrounded)
+	backgrounded bool // Game tab was hidden at the last check
 }
 
 // NewBot creates and initializes a new bot instance with all required components.
@@ -134,16 +175,32 @@ func NewBot() *Bot {
 		data = NewPersistentData()
 	}
 
+	SetLogFormat(data.Config.LogFormat)
 	LogDebug("Config loaded")
 	stats := NewStatistics()
+	stats.RestoreMobKills(data.MobKills)
 	LogDebug("Statistics created")
-	browser := NewBrowser()
+	browser := NewBrowser(data.Config)
 	LogDebug("Browser created")
-	action := NewAction(browser)
+	action := NewAction(browser, data.Config)
 	LogDebug("Action created")
 	analyzer := NewImageAnalyzer(browser)
+	analyzer.GetStats().SetBarSelectRules(data.Config.BarSelectRules)
+	analyzer.GetStats().SetBarColors(data.Config.BarColors)
+	analyzer.SetStatusRecalibrateInterval(data.Config.StatusRecalibrateInterval)
+	analyzer.SetPickupPetIcon(data.Config.PickupPetIcon)
+	analyzer.SetPartyInviteTemplate(data.Config.PartyInviteTemplate)
+	analyzer.SetDebuffIcons(data.Config.DebuffIcons)
+	gameMessages = LoadGameMessages(data.Config.GameLanguage)
+	analyzer.SetMessageTemplates(gameMessages)
+	if data.Config.ReadActionBarCooldowns {
+		actionBar := data.Config.ActionBarArea
+		analyzer.SetActionBar(&actionBar)
+	}
 	LogDebug("Image analyzer created")
-	movement := NewMovementCoordinator(action, browser)
+	movement := NewMovementCoordinator(action, browser, data.Config)
+	movement.SetClientStats(analyzer.GetStats())
+	movement.SetScreenInfo(analyzer.screenInfo)
 	LogDebug("Movement coordinator created")
 
 	bot := &Bot{
@@ -155,13 +212,22 @@ func NewBot() *Bot {
 		movement: movement,
 		stopChan: make(chan bool),
 		data:     data,
+		modeRequests: make(chan string, 1),
+		slotTests:    make(chan int, 1),
+		sessionStart: stats.StartTime,
 		debugOverlayChan: make(chan *DebugOverlayRequest, 10), // Buffered channel for non-blocking sends
 		debugOverlayEnabled: true, // Can be toggled via config later
+		snapshotChan: make(chan *image.RGBA, 2), // Buffered channel for non-blocking sends
 	}
 
 	// Create system tray UI
 	LogInfo("Creating system tray UI...")
 	bot.tray = NewTrayApp(bot)
+
+	// Create HTTP API if enabled
+	if data.Config.APIPort > 0 {
+		bot.api = NewAPIServer(bot, data.Config.APIPort, data.Config.APIToken)
+	}
 	LogInfo("Bot components initialized successfully")
 
 	return bot
@@ -208,9 +274,17 @@ func (b *Bot) StartMainLoop() {
 		})
 	}
 
+	// Start async snapshot worker (idle while Config.SnapshotIntervalSec is 0)
+	SafeGo(func() {
+		b.snapshotWorker()
+	})
+
 	// Set initial mode immediately
 	b.ChangeMode("Farming")
 
+	// Register the global pause hotkey if configured
+	b.startPauseHotkey()
+
 	// Start main loop immediately (don't wait for browser)
 	LogInfo("Starting main loop immediately (browser will continue loading in background)")
 	SafeGo(func() {
@@ -262,7 +336,7 @@ func (b *Bot) debugOverlayWorker() {
 //   - "Stop": Disables all bot actions, only image recognition continues
 //   - "Farming": Activates autonomous mob hunting and item collection
 //   - "Support": Enables party member following, healing, and buffing
-//   - "Shouting": Placeholder for auto-chat functionality (not yet implemented)
+//   - "Shouting": Periodically types and sends the configured chat messages
 //
 // Parameters:
 //   - mode: String identifier for the desired mode (case-sensitive)
@@ -270,28 +344,44 @@ func (b *Bot) debugOverlayWorker() {
 // Algorithm:
 //   1. Log the mode change request
 //   2. Update thread-safe config.Mode value
-//   3. Stop current behavior if one is active
+//   3. Stop current behavior if one is active and release all held keys
 //   4. Create new behavior instance based on mode selection
 //   5. Log confirmation of behavior activation
 //
 // Thread Safety:
 // Uses config.SetMode() which is mutex-protected for concurrent access safety.
-// Safe to call from any goroutine including system tray event handlers.
+// Stops the current behavior and sends its cleanup keys, so it must run on the
+// main loop; other goroutines (tray, HTTP API, hotkey) use RequestMode.
 func (b *Bot) ChangeMode(mode string) {
 	LogInfo("Changing mode to: %s", mode)
+	previous := b.config.GetMode()
 	b.config.SetMode(mode)
+	if mode != "Stop" {
+		b.sessionComplete = ""
+	}
+
+	// A session starts when the bot leaves Stop, time spent stopped does not
+	// count toward Config.MaxRuntimeMinutes
+	if previous == "Stop" && mode != "Stop" {
+		b.sessionStart = time.Now()
+		b.sessionKills, _, _, _, _ = b.stats.GetStats()
+	}
 
 	// Stop current behavior if any
 	if b.behavior != nil {
 		b.behavior.Stop()
 	}
 
+	// Never carry held movement keys over to the next mode
+	b.action.ReleaseAllHeld()
+
 	// Create appropriate behavior based on mode
 	switch mode {
 	case "Stop":
 		b.behavior = nil
 		LogInfo("Bot stopped, image recognition continues")
 	case "Farming":
+		b.analyzer.GetStats().ResetInventoryFull()
 		b.behavior = NewFarmingBehavior()
 		LogInfo("Farming behavior activated")
 	case "Support":
@@ -305,6 +395,25 @@ func (b *Bot) ChangeMode(mode string) {
 	}
 }
 
+// RequestMode queues a mode change for the main loop, so the current behavior
+// is stopped (and its cleanup keys are sent) between iterations instead of from
+// the calling goroutine. A newer request replaces a pending one.
+func (b *Bot) RequestMode(mode string) {
+	for {
+		select {
+		case b.modeRequests <- mode:
+			return
+		default:
+			// Drop the pending request, the latest selection wins
+			select {
+			case previous := <-b.modeRequests:
+				LogDebug("Mode change to %s replaced by %s", previous, mode)
+			default:
+			}
+		}
+	}
+}
+
 // StopBehavior gracefully stops the current active behavior and signals the main loop to terminate.
 //
 // Algorithm:
@@ -328,6 +437,7 @@ func (b *Bot) StopBehavior() {
 	if b.behavior != nil {
 		b.behavior.Stop()
 	}
+	b.action.ReleaseAllHeld()
 
 	// Close debug overlay channel to signal worker to exit
 	if b.debugOverlayChan != nil {
@@ -365,6 +475,11 @@ func (b *Bot) StopBehavior() {
 // Timing Modes:
 //   - CaptureInterval = 0: Continuous execution (no sleep between iterations)
 //   - CaptureInterval > 0: Waits for specified milliseconds between iterations
+//   - PreferredInterval: The active behavior overrides CaptureInterval (see captureInterval)
+//   - AdaptiveCapture: The behavior picks the interval per state (see captureInterval)
+//
+// The interval is read every pass, so a mode change applies the new behavior's
+// interval right away instead of after the previous wait.
 //
 // Performance:
 // Uses 50ms sleep intervals when waiting, resulting in ~5% CPU usage instead
@@ -373,23 +488,41 @@ func (b *Bot) mainLoop() {
 	LogInfo("Main loop started")
 
 	lastCaptureTime := time.Now()
+	lastInterval := -1
 
 	for {
 		select {
 		case <-b.stopChan:
 			LogInfo("Stop signal received")
 			return
+		case mode := <-b.modeRequests:
+			// Queued mode change (tray, HTTP API, pause hotkey), applied between iterations
+			if b.tray != nil {
+				b.tray.onModeClicked(mode)
+			} else {
+				b.ChangeMode(mode)
+			}
+		case slot := <-b.slotTests:
+			// Queued tray slot test, run between iterations
+			b.testSlot(slot)
 		default:
 			// Get current capture interval
-			b.config.mu.RLock()
-			captureInterval := b.config.CaptureInterval
-			b.config.mu.RUnlock()
+			captureInterval := b.captureInterval()
+			if captureInterval != lastInterval {
+				LogDebug("Capture interval: %dms", captureInterval)
+				lastInterval = captureInterval
+			}
 
 			// Check if enough time has passed since last capture
 			now := time.Now()
 			timeSinceCapture := now.Sub(lastCaptureTime)
 
 			if captureInterval == 0 || timeSinceCapture >= time.Duration(captureInterval)*time.Millisecond {
+				if b.pausedInBackground() {
+					// Slow heartbeat, only waiting for the tab to come back
+					time.Sleep(backgroundHeartbeat)
+					continue
+				}
 				b.runIteration()
 				lastCaptureTime = now
 			} else {
@@ -400,6 +533,56 @@ func (b *Bot) mainLoop() {
 	}
 }
 
+// backgroundHeartbeat is how often a hidden game tab is checked for coming back
+const backgroundHeartbeat = 2 * time.Second
+
+// pausedInBackground reports whether the main loop should pause because
+// Config.PauseWhenBackgrounded is set and the game tab is hidden. Held keys are
+// released when the pause starts so the character does not keep walking.
+func (b *Bot) pausedInBackground() bool {
+	b.config.mu.RLock()
+	enabled := b.config.PauseWhenBackgrounded
+	b.config.mu.RUnlock()
+
+	hidden := enabled && b.browser.IsPageHidden()
+	if hidden != b.backgrounded {
+		b.backgrounded = hidden
+		if hidden {
+			LogInfo("Game tab hidden, pausing until it is visible again")
+			b.action.ReleaseAllHeld()
+		} else {
+			LogInfo("Game tab visible again, resuming")
+		}
+	}
+	return hidden
+}
+
+// captureInterval returns the interval in ms between iterations, in order of precedence:
+// the interval the current behavior asks for its state when Config.AdaptiveCapture is
+// enabled, the behavior's PreferredInterval, then Config.CaptureInterval
+func (b *Bot) captureInterval() int {
+	b.config.mu.RLock()
+	interval := b.config.CaptureInterval
+	adaptive := b.config.AdaptiveCapture
+	b.config.mu.RUnlock()
+
+	behavior := b.behavior
+	if behavior == nil {
+		return interval
+	}
+	if adaptive {
+		if adaptiveBehavior, ok := behavior.(AdaptiveCaptureBehavior); ok {
+			if hint := adaptiveBehavior.CaptureInterval(b.config); hint >= 0 {
+				return hint
+			}
+		}
+	}
+	if preferred := behavior.PreferredInterval(b.config); preferred > 0 {
+		return preferred
+	}
+	return interval
+}
+
 // runIteration executes a single cycle of the bot's operation pipeline.
 //
 // This is the core function that orchestrates all bot activities in sequence.
@@ -410,7 +593,7 @@ func (b *Bot) mainLoop() {
 //      - Returns early if canvas not found
 //   2. Capture screenshot from browser (5s timeout)
 //      - Returns early on failure
-//   3. Set captured image to analyzer for processing
+//   3. Set captured image to analyzer for processing (static frames reuse detections)
 //   4. Update player statistics (HP/MP/FP bar recognition)
 //   5. Get current client stats (percentage values, alive state)
 //   6. Identify mobs in current frame (yellow passive, red aggressive)
@@ -450,6 +633,9 @@ func (b *Bot) runIteration() {
 		LogInfo("Cookies saved successfully after game load")
 	}
 
+	// Check system messages for disconnects and errors
+	b.checkChatMessages()
+
 	// Capture screen from browser and store in analyzer
 	LogDebug("runIteration: calling Capture")
 	img, err := b.browser.Capture()
@@ -459,11 +645,14 @@ func (b *Bot) runIteration() {
 	}
 	LogDebug("runIteration: capture returned, img != nil: %v", img != nil)
 
-	// Store captured image in analyzer
+	// Store captured image in analyzer, marking frames that did not change
 	if img != nil {
-		b.analyzer.mu.Lock()
-		b.analyzer.lastImage = img
-		b.analyzer.mu.Unlock()
+		b.config.mu.RLock()
+		skipStatic := b.config.SkipStaticFrames
+		threshold := b.config.StaticFrameThreshold
+		b.config.mu.RUnlock()
+		b.analyzer.SetFrame(img, skipStatic, threshold)
+		b.queueSnapshot(img)
 	}
 
 	// Update stats before drawing overlay
@@ -510,14 +699,217 @@ func (b *Bot) runIteration() {
 		if err != nil {
 			LogError("Behavior error: %v", err)
 		}
+		b.checkAntiIdle()
+	}
+
+	// Stop once the session kill/time limit is reached
+	if mode != "Stop" && b.checkSessionLimits() {
+		mode = b.config.GetMode()
 	}
 
+	b.publishStatus()
+
 	// Update tray status
 	if b.tray != nil {
 		b.tray.UpdateStatus(mode)
 	}
 }
 
+// publishStatus stores the state of the current behavior for BehaviorStatus.
+// Called by the main loop, the only goroutine running the behavior.
+func (b *Bot) publishStatus() {
+	stage, halted := "Idle", ""
+	if b.behavior != nil {
+		stage = b.behavior.GetState()
+		if fb, ok := b.behavior.(*FarmingBehavior); ok {
+			halted = fb.HaltReason()
+		}
+	}
+
+	b.statusMu.Lock()
+	b.statusStage = stage
+	b.statusHalted = halted
+	b.statusMu.Unlock()
+}
+
+// BehaviorStatus returns the behavior state and halt reason published by the
+// main loop after its last iteration (thread-safe)
+func (b *Bot) BehaviorStatus() (string, string) {
+	b.statusMu.Lock()
+	defer b.statusMu.Unlock()
+	if b.statusStage == "" {
+		return "Idle", b.statusHalted
+	}
+	return b.statusStage, b.statusHalted
+}
+
+// antiIdleNudgeDuration is how long each camera key is held for an anti-idle nudge
+const antiIdleNudgeDuration = 50 * time.Millisecond
+
+// checkAntiIdle sends a harmless input (Config.AntiIdleAction) when no key or
+// mouse action was sent for Config.AntiIdleMs, so the game does not log the
+// character out as AFK during long searches. Nothing is sent while keys are
+// held down (navigation) or while the behavior does not allow it (fighting).
+func (b *Bot) checkAntiIdle() {
+	b.config.mu.RLock()
+	antiIdleMs := b.config.AntiIdleMs
+	antiIdleAction := b.config.AntiIdleAction
+	b.config.mu.RUnlock()
+
+	if antiIdleMs <= 0 {
+		return
+	}
+	idle := b.action.IdleFor()
+	if idle < time.Duration(antiIdleMs)*time.Millisecond {
+		return
+	}
+	if len(b.action.HeldKeys()) > 0 {
+		return
+	}
+	if behavior, ok := b.behavior.(AntiIdleBehavior); ok && !behavior.AntiIdleAllowed() {
+		return
+	}
+
+	LogInfo("No input for %v, sending anti-idle %s", idle.Round(time.Second), antiIdleAction)
+	switch antiIdleAction {
+	case AntiIdleJump:
+		b.movement.Jump()
+	default:
+		// Turn the camera a little and back, so the view stays where it was
+		b.movement.RotateRight(antiIdleNudgeDuration)
+		b.movement.RotateLeft(antiIdleNudgeDuration)
+	}
+}
+
+// testSlot uses a slot once for the tray "Test Slot" menu, switching to its
+// skill bar page like the behaviors do. The behavior state is left
+// alone. Does nothing until the game canvas is loaded.
+func (b *Bot) testSlot(slotNum int) {
+	if !b.browser.CheckCanvasExists() {
+		LogWarn("Slot test: game not ready, slot %d not used", slotNum)
+		return
+	}
+
+	LogInfo("Slot test: using slot %d", slotNum)
+	b.movement.UseSlot(slotNum)
+}
+
+// checkSessionLimits stops the bot when the session reached Config.MaxKills or
+// Config.MaxRuntimeMinutes, then presses Config.SessionEndKeys (e.g. to log out).
+// The next session counts from the next switch out of Stop (see ChangeMode).
+// Returns true if the session completed.
+func (b *Bot) checkSessionLimits() bool {
+	b.config.mu.RLock()
+	maxKills := b.config.MaxKills
+	maxMinutes := b.config.MaxRuntimeMinutes
+	endKeys := append([]string{}, b.config.SessionEndKeys...)
+	b.config.mu.RUnlock()
+
+	if maxKills <= 0 && maxMinutes <= 0 {
+		return false
+	}
+
+	kills, _, _, _, _ := b.stats.GetStats()
+	kills -= b.sessionKills
+	runtime := time.Since(b.sessionStart)
+
+	reason := ""
+	if maxKills > 0 && kills >= maxKills {
+		reason = fmt.Sprintf("%d kills", kills)
+	} else if maxMinutes > 0 && runtime >= time.Duration(maxMinutes)*time.Minute {
+		reason = fmt.Sprintf("%d minutes", maxMinutes)
+	} else {
+		return false
+	}
+
+	LogInfo("Session complete after %s (%d kills in %s), stopping", reason, kills, FormatDuration(runtime))
+	if b.tray != nil {
+		b.tray.onModeClicked("Stop")
+	} else {
+		b.ChangeMode("Stop")
+	}
+
+	for _, key := range endKeys {
+		LogInfo("Session end: pressing %s", key)
+		b.movement.PressKey(key)
+		b.movement.Wait(500 * time.Millisecond)
+	}
+
+	b.sessionComplete = reason
+	return true
+}
+
+// SessionComplete returns why the last session completed, or "" while no
+// completed session is being shown
+func (b *Bot) SessionComplete() string {
+	return b.sessionComplete
+}
+
+// checkChatMessages reads new chat/system lines and reacts to disconnects and errors.
+//
+// Only lines after the last handled one are processed so each message is logged once.
+// A disconnect message stops the active behavior to avoid sending input to a dead session.
+func (b *Bot) checkChatMessages() {
+	lines := b.browser.ReadChatLines(10)
+	if len(lines) == 0 {
+		return
+	}
+
+	// Skip lines already handled in a previous iteration
+	start := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i] == b.lastChatLine {
+			start = i + 1
+			break
+		}
+	}
+	newLines := lines[start:]
+	b.lastChatLine = lines[len(lines)-1]
+
+	if len(newLines) == 0 {
+		return
+	}
+
+	// Switch detection profile when entering a new map
+	for _, line := range newLines {
+		if match := gameMessages.Match(line, MessageMapEnter); len(match) > 1 {
+			b.switchMapProfile(match[1])
+		}
+	}
+
+	if line, ok := gameMessages.FindLine(newLines, MessageDisconnect); ok {
+		LogError("Disconnect detected: %s", line)
+		b.stats.AddDisconnect()
+		if b.config.GetMode() != "Stop" {
+			b.ChangeMode("Stop")
+		}
+		return
+	}
+
+	if line, ok := gameMessages.FindLine(newLines, MessageGameError); ok {
+		LogWarn("Game error message: %s", line)
+	}
+}
+
+// switchMapProfile applies the detection profile for mapName if the map changed
+func (b *Bot) switchMapProfile(mapName string) {
+	b.config.mu.RLock()
+	current := b.config.CurrentMap
+	b.config.mu.RUnlock()
+
+	if mapName == current {
+		return
+	}
+
+	if b.config.ApplyMapProfile(mapName) {
+		LogInfo("Entered %s, map profile applied", mapName)
+	} else {
+		LogInfo("Entered %s, no map profile, using base detection settings", mapName)
+	}
+
+	b.analyzer.GetStats().SetBarSelectRules(b.config.Detection().BarSelectRules)
+}
+
 // SaveState persists the current bot configuration and browser cookies to data.json.
 //
 // This function is called during:
@@ -535,7 +927,8 @@ func (b *Bot) runIteration() {
 //
 // Data Saved:
 //   - Configuration: Mode, slot assignments, thresholds, mob colors, behavior settings
-//   - Cookies: All browser cookies from universe.flyff.com domain for session persistence
+//   - Kill statistics: Per-mob kill counts
+//   - Cookies: All browser cookies of the game domain (Config.GameURL) for session persistence
 //
 // Error Handling:
 // Cookie retrieval failure is logged as warning but does not prevent config save.
@@ -552,6 +945,10 @@ func (b *Bot) SaveState() {
 		LogInfo("Saved %d cookies", len(cookies))
 	}
 
+	// Save per-mob kill breakdown
+	_, _, _, _, mobKills := b.stats.GetStats()
+	b.data.MobKills = mobKills
+
 	// Save data to file
 	err = SaveData(b.data)
 	if err != nil {
@@ -573,13 +970,14 @@ func (b *Bot) SaveState() {
 //      - Triggers graceful shutdown sequence:
 //        a. Stop active behavior
 //        b. Save configuration and cookies
-//        c. Close browser
+//        c. Close browser and HTTP API
 //        d. Close log file
 //        e. Exit with code 0
-//   2. Start system tray UI (blocking call)
+//   2. Start HTTP API if enabled
+//   3. Start system tray UI (blocking call)
 //      - Tray initialization triggers StartMainLoop() asynchronously
 //      - Function blocks here until systray.Quit() is called
-//   3. Perform final state save after tray exits
+//   4. Perform final state save after tray exits
 //
 // Signal Handling:
 // Uses a goroutine to listen for OS signals without blocking the main thread.
@@ -602,8 +1000,10 @@ func (b *Bot) Run() {
 		b.StopBehavior()
 		LogInfo("Saving state...")
 		b.SaveState()
+		b.writeSessionReport()
 		LogInfo("Closing browser...")
 		b.browser.Close()
+		b.StopAPI()
 		LogInfo("Closing logger...")
 		CloseLogger()
 		LogInfo("Exiting with code 0")
@@ -612,6 +1012,10 @@ func (b *Bot) Run() {
 
 	LogInfo("Signal handlers configured")
 
+	if b.api != nil {
+		b.api.Start()
+	}
+
 	// Run system tray (blocking) - tray will trigger browser start
 	LogInfo("Starting system tray (browser will start when tray is ready)...")
 	b.tray.Run()
@@ -622,6 +1026,14 @@ func (b *Bot) Run() {
 	b.SaveState()
 }
 
+// StopAPI shuts down the HTTP API if it is running
+func (b *Bot) StopAPI() {
+	if b.api != nil {
+		LogInfo("Stopping HTTP API...")
+		b.api.Shutdown()
+	}
+}
+
 // main is the application entry point that initializes logging and starts the bot.
 //
 // Initialization Sequence:
@@ -681,7 +1093,7 @@ func main() {
 	// Check for --train flag
 	if len(os.Args) > 1 && os.Args[1] == "--train" {
 		LogInfo("Training mode requested")
-		if err := TrainingMode(); err != nil {
+		if err := TrainingMode(len(os.Args) > 2 && os.Args[2] == "--live"); err != nil {
 			LogError("Training mode failed: %v", err)
 			os.Exit(1)
 		}
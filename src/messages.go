@@ -0,0 +1,192 @@
+// Package main - messages.go
+//
+// This file maps logical game events to the chat text or on-screen message
+// that signals them, so message-driven features work with any game language.
+//
+// Events are matched in one of three ways:
+//   - text: phrases found in chat/system lines (case-insensitive)
+//   - pattern: a regexp matched on chat/system lines, group 1 captures a name
+//   - template: an image of the on-screen message (inventory full, out of
+//     range), matched instead of reading the message with OCR
+//
+// On-screen messages without a template are read with OCR (tesseract) once
+// their red text shows up, and must contain one of the event's text phrases.
+//
+// Built-in English messages are always loaded. messages.json, if present,
+// overrides them per language:
+//
+//	{
+//	  "en": {"disconnect": {"text": ["disconnected", "connection lost"]}},
+//	  "de": {
+//	    "disconnect": {"text": ["Verbindung getrennt"]},
+//	    "inventory_full": {"template": "messages/de/inventory_full.png"}
+//	  }
+//	}
+//
+// Config.GameLanguage selects the language at startup. Events the language
+// does not define fall back to English.
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+)
+
+const messagesFile = "messages.json"
+
+// Logical game events (keys of messages.json)
+const (
+	MessageDefeated      = "defeated"       // Kill confirmation (text)
+	MessageDisconnect    = "disconnect"     // Connection lost (text)
+	MessageGameError     = "game_error"     // Generic error, e.g. not enough MP (text)
+	MessageMapEnter      = "map_enter"      // Entering a map (pattern, group 1 = map name)
+	MessagePartyInvite   = "party_invite"   // Party invite (pattern, group 1 = player)
+	MessageTradeRequest  = "trade_request"  // Trade request (pattern, group 1 = player)
+	MessageInventoryFull = "inventory_full" // "Inventory full" message (template, or text read by OCR)
+	MessageOutOfRange    = "out_of_range"   // "Target out of range" message (template, or text read by OCR)
+)
+
+// GameMessage describes how one game event is recognized
+type GameMessage struct {
+	Text     []string `json:"text,omitempty"`     // Phrases matched in chat lines
+	Pattern  string   `json:"pattern,omitempty"`  // Regexp matched on chat lines
+	Template string   `json:"template,omitempty"` // Template image of the on-screen message ("" = OCR of the text)
+
+	pattern *regexp.Regexp
+}
+
+// GameMessages holds the messages of the configured game language
+type GameMessages struct {
+	Language string
+	messages map[string]*GameMessage
+}
+
+// gameMessages are the messages used by the behaviors, replaced at startup by
+// LoadGameMessages
+var gameMessages = defaultGameMessages()
+
+// defaultGameMessages returns the built-in English messages
+func defaultGameMessages() *GameMessages {
+	gm := &GameMessages{Language: "en", messages: make(map[string]*GameMessage)}
+	gm.set(MessageDefeated, &GameMessage{Text: []string{"You have defeated"}})
+	gm.set(MessageDisconnect, &GameMessage{Text: []string{"disconnected", "connection lost"}})
+	gm.set(MessageGameError, &GameMessage{Text: []string{"not enough", "cannot", "can't"}})
+	gm.set(MessageMapEnter, &GameMessage{Pattern: `(?i)you have entered (?:the )?(.+?)\.?$`})
+	gm.set(MessagePartyInvite, &GameMessage{Pattern: `(?i)^(\S+) (?:has )?invited you to (?:a|the|their) party`})
+	gm.set(MessageTradeRequest, &GameMessage{Pattern: `(?i)^(\S+) (?:requests|wants) (?:a )?trade`})
+	gm.set(MessageInventoryFull, &GameMessage{Text: []string{"inventory is full", "inventory full"}})
+	gm.set(MessageOutOfRange, &GameMessage{Text: []string{"out of range", "too far"}})
+	return gm
+}
+
+// LoadGameMessages loads the messages of language from messages.json on top of
+// the built-in English ones. A missing or invalid file keeps English.
+func LoadGameMessages(language string) *GameMessages {
+	gm := defaultGameMessages()
+	if language == "" {
+		language = "en"
+	}
+
+	data, err := os.ReadFile(messagesFile)
+	if os.IsNotExist(err) {
+		if language != "en" {
+			LogWarn("No %s found, using English game messages instead of %q", messagesFile, language)
+		}
+		return gm
+	}
+	if err != nil {
+		LogError("Failed to read %s: %v", messagesFile, err)
+		return gm
+	}
+
+	var languages map[string]map[string]*GameMessage
+	if err := json.Unmarshal(data, &languages); err != nil {
+		LogError("Failed to decode %s: %v", messagesFile, err)
+		return gm
+	}
+
+	// English first, so other languages fall back to an edited English entry
+	for _, name := range []string{"en", language} {
+		for event, message := range languages[name] {
+			if message != nil {
+				gm.set(event, message)
+			}
+		}
+	}
+	if language != "en" {
+		if _, ok := languages[language]; !ok {
+			LogWarn("No %q game messages in %s, using English", language, messagesFile)
+			return gm
+		}
+	}
+
+	gm.Language = language
+	LogInfo("Game messages loaded from %s (%s)", messagesFile, language)
+	return gm
+}
+
+// set stores message for event, compiling its pattern.
+// An invalid pattern keeps the current message of the event.
+func (gm *GameMessages) set(event string, message *GameMessage) {
+	if message.Pattern != "" {
+		pattern, err := regexp.Compile(message.Pattern)
+		if err != nil {
+			LogWarn("Invalid %s message pattern %q: %v", event, message.Pattern, err)
+			return
+		}
+		message.pattern = pattern
+	}
+	gm.messages[event] = message
+}
+
+// FindLine reports the last line matching the text or pattern of event
+func (gm *GameMessages) FindLine(lines []string, event string) (string, bool) {
+	message := gm.messages[event]
+	if message == nil {
+		return "", false
+	}
+
+	if len(message.Text) > 0 {
+		if line, ok := ChatLinesContain(lines, message.Text...); ok {
+			return line, true
+		}
+	}
+	if message.pattern != nil {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if message.pattern.MatchString(lines[i]) {
+				return lines[i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// ContainsText reports whether text (e.g. an on-screen message read by OCR)
+// contains one of the text phrases of event
+func (gm *GameMessages) ContainsText(text, event string) bool {
+	message := gm.messages[event]
+	if message == nil || len(message.Text) == 0 {
+		return false
+	}
+	_, ok := ChatLinesContain([]string{text}, message.Text...)
+	return ok
+}
+
+// Match matches line against the pattern of event.
+// Returns the submatches, nil if the line does not match or event has no pattern.
+func (gm *GameMessages) Match(line, event string) []string {
+	message := gm.messages[event]
+	if message == nil || message.pattern == nil {
+		return nil
+	}
+	return message.pattern.FindStringSubmatch(line)
+}
+
+// Template returns the template image of event ("" = none)
+func (gm *GameMessages) Template(event string) string {
+	if message := gm.messages[event]; message != nil {
+		return message.Template
+	}
+	return ""
+}
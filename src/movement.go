@@ -5,7 +5,7 @@
 //
 // Key Responsibilities:
 //   - Character Movement: Forward, backward, rotation, jumping, circle patterns
-//   - Skill Execution: Using numbered hotkey slots (0-9)
+//   - Skill Execution: Using numbered hotkey slots (0-9) on skill bar pages F1-F9
 //   - Target Management: Clicking mobs, locking/canceling targets
 //   - Obstacle Avoidance: Jump maneuvers in random directions
 //   - Chat Interaction: Opening chat and sending messages
@@ -16,7 +16,8 @@
 //   - W/A/S/D: Character movement
 //   - Space: Jump
 //   - Left/Right Arrow: Camera rotation
-//   - 0-9: Skill/item hotkey slots
+//   - Right-click drag: Camera rotation (config.CameraRotateMode "drag")
+//   - F1-F9 + 0-9: Skill bar page and skill/item hotkey slot
 //   - Z: Target lock / Follow target
 //   - Escape: Cancel target
 //   - Enter: Open/send chat
@@ -39,6 +40,7 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"time"
 )
@@ -52,6 +54,11 @@ import (
 //   - action: Low-level keyboard/mouse input simulation via JavaScript
 //   - browser: Action logging for debug visualization
 //   - rng: Random number generator for varied movement patterns
+//   - config/slotLastUsed: Per-slot cooldowns (config.SlotCooldowns) shared by all behaviors
+//   - currentPage: Skill bar page (F1-F9) last switched to, for slots on other pages (slot numbers 10-89)
+//   - stats: Current FP, slots costing more FP than left are skipped (config.SlotFPCost),
+//     chat focus, closed before movement keys are held, and the action bar cooldown
+//     sweeps correcting the slot timers (config.ReadActionBarCooldowns)
 //
 // Thread Safety:
 // Not thread-safe. Should only be called from the main loop goroutine.
@@ -61,14 +68,23 @@ type MovementCoordinator struct {
 	browser    *Browser
 	rng        *rand.Rand
 	screenInfo *ScreenInfo
+
+	config       *Config           // Source of SlotCooldowns
+	slotLastUsed map[int]time.Time // slot number -> last usage time
+	currentPage  int               // Skill bar page last switched to (0 = never switched, page F1 assumed)
+
+	stats      *ClientStats // Source of the current FP for SlotFPCost, ChatFocused and SlotCooling (nil = not checked)
+	fpShortage bool         // A slot was skipped for low FP since the last TakeFPShortage
 }
 
 // NewMovementCoordinator creates a new movement coordinator
-func NewMovementCoordinator(action *Action, browser *Browser) *MovementCoordinator {
+func NewMovementCoordinator(action *Action, browser *Browser, config *Config) *MovementCoordinator {
 	return &MovementCoordinator{
-		action:  action,
-		browser: browser,
-		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		action:       action,
+		browser:      browser,
+		rng:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		config:       config,
+		slotLastUsed: make(map[int]time.Time),
 	}
 }
 
@@ -81,11 +97,30 @@ func (mc *MovementCoordinator) PressKey(key string) {
 	time.Sleep(10 * time.Millisecond)
 }
 
-// HoldKey holds a key down
+// movementKeys are the keys that type into the chat instead of moving while it is focused
+var movementKeys = map[string]bool{"w": true, "a": true, "s": true, "d": true, "space": true}
+
+// HoldKey holds a key down. Closes a focused chat first for movement keys.
 func (mc *MovementCoordinator) HoldKey(key string) {
+	if movementKeys[key] {
+		mc.EnsureChatClosed()
+	}
 	mc.action.SendKey(key, KeyHold)
 }
 
+// EnsureChatClosed presses Escape when the chat input is focused (e.g. left
+// open after a shout), so movement keys move instead of typing into the chat
+func (mc *MovementCoordinator) EnsureChatClosed() {
+	if mc.stats == nil || !mc.stats.IsChatFocused() {
+		return
+	}
+	LogDebug("Chat input focused, closing it before moving")
+	mc.PressKey("escape")
+	// Don't press Escape again before the next frame confirms the chat state
+	mc.stats.SetChatFocusDetected(false)
+	mc.Wait(50 * time.Millisecond)
+}
+
 // ReleaseKey releases a held key
 func (mc *MovementCoordinator) ReleaseKey(key string) {
 	mc.action.SendKey(key, KeyRelease)
@@ -153,6 +188,55 @@ func (mc *MovementCoordinator) RotateRight(duration time.Duration) {
 	mc.ReleaseKey("right")
 }
 
+// keyRotateMsPerDegree is how long an arrow key is held per degree of camera rotation
+const keyRotateMsPerDegree = 5
+
+// dragRotateStep is the distance in pixels of each mousemove event of DragRotate
+const dragRotateStep = 8
+
+// Rotate turns the camera by degrees, positive turns right. Uses a right-click
+// drag when config.CameraRotateMode is "drag", arrow keys otherwise or when the
+// drag fails.
+func (mc *MovementCoordinator) Rotate(degrees float64) {
+	mc.config.mu.RLock()
+	mode := mc.config.CameraRotateMode
+	mc.config.mu.RUnlock()
+
+	if mode == CameraRotateDrag {
+		if err := mc.DragRotate(degrees); err == nil {
+			return
+		}
+		LogDebug("Drag rotation failed, falling back to arrow keys")
+	}
+
+	duration := time.Duration(math.Abs(degrees)*keyRotateMsPerDegree) * time.Millisecond
+	if degrees < 0 {
+		mc.RotateLeft(duration)
+	} else if degrees > 0 {
+		mc.RotateRight(duration)
+	}
+}
+
+// DragRotate turns the camera by degrees with a right-click drag from the
+// screen center, positive turns right. The drag distance is
+// config.DragPixelsPerDegree per degree.
+func (mc *MovementCoordinator) DragRotate(degrees float64) error {
+	mc.config.mu.RLock()
+	pixelsPerDegree := mc.config.DragPixelsPerDegree
+	mc.config.mu.RUnlock()
+
+	dx := int(math.Round(degrees * pixelsPerDegree))
+	if dx == 0 {
+		return nil
+	}
+
+	if mc.screenInfo == nil {
+		return fmt.Errorf("screen size unknown")
+	}
+	center := mc.screenInfo.Center()
+	return mc.action.RightDrag(center.X, center.Y, dx, dragRotateStep)
+}
+
 // RotateRandom rotates in a random direction
 func (mc *MovementCoordinator) RotateRandom(duration time.Duration) {
 	if mc.rng.Float64() < 0.5 {
@@ -220,60 +304,192 @@ func (mc *MovementCoordinator) ClickTarget(point Point) {
 	}
 }
 
-// UseSlot uses a skill/item slot (F1-F9 + number 0-9)
+// ClickTargetWithin clicks on a target, keeping humanized click offsets inside bounds
+func (mc *MovementCoordinator) ClickTargetWithin(point Point, bounds Bounds) {
+	LogDebug("Clicking target at (%d, %d) within %dx%d", point.X, point.Y, bounds.W, bounds.H)
+	mc.action.ClickWithin(point, bounds)
+	if mc.browser != nil {
+		mc.browser.LogAction(fmt.Sprintf("Click at (%d, %d)", point.X, point.Y))
+	}
+}
+
+// UseSlot uses a skill/item slot. Slot numbers 0-9 are on page F1, 10-19 on
+// page F2 and so on (see SlotPage). A slot on the page already showing is used
+// with its number key, a slot on another page with Action.SendSlot, which
+// presses the page's F-key first.
 func (mc *MovementCoordinator) UseSlot(slotNum int) {
-	if slotNum < 0 || slotNum > 9 {
+	if slotNum < 0 || slotNum >= SlotsPerPage*SkillPages {
 		LogWarn("Invalid slot number: %d", slotNum)
 		return
 	}
 
-	// Note: In Flyff, F1-F9 are slot bars, and 0-9 are the slots within each bar
-	// We'll use F1 as the default bar and numbers 0-9 for slots
-	// For full implementation, you'd need bar selection logic
-
-	key := ""
-	switch slotNum {
-	case 0:
-		key = "0"
-	case 1:
-		key = "1"
-	case 2:
-		key = "2"
-	case 3:
-		key = "3"
-	case 4:
-		key = "4"
-	case 5:
-		key = "5"
-	case 6:
-		key = "6"
-	case 7:
-		key = "7"
-	case 8:
-		key = "8"
-	case 9:
-		key = "9"
-	}
-
-	if key != "" {
-		LogDebug("Using slot %d", slotNum)
-		mc.PressKey(key)
-	}
-}
-
-// UseSkill uses a skill from configured slot list
-func (mc *MovementCoordinator) UseSkill(slots []int) bool {
-	if len(slots) == 0 {
+	page, slot := SlotPage(slotNum)
+	LogDebug("Using slot %d (F%d-%d)", slotNum, page, slot)
+	if page == mc.showingPage() {
+		mc.PressKey(fmt.Sprintf("%d", slot))
+	} else {
+		if err := mc.action.SendSlot(page-1, slot); err != nil {
+			return
+		}
+		mc.currentPage = page
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if mc.slotLastUsed != nil {
+		mc.slotLastUsed[slotNum] = time.Now()
+	}
+}
+
+// showingPage returns the skill bar page currently showing. Until a slot on
+// another page was used, page F1 is assumed.
+func (mc *MovementCoordinator) showingPage() int {
+	if mc.currentPage == 0 {
+		return 1
+	}
+	return mc.currentPage
+}
+
+// actionBarReadDelay is how long after a slot press the action bar must have
+// been read before its icon is trusted, the sweep takes a moment to appear
+const actionBarReadDelay = 500 * time.Millisecond
+
+// SlotReady reports whether a slot's configured cooldown has expired.
+// Slots without a configured cooldown are always ready.
+// With config.ReadActionBarCooldowns the cooldown sweep on the slot's action
+// bar icon overrides the timer: a slot still cooling down is not ready, and a
+// slot whose sweep is gone has its timer cleared.
+func (mc *MovementCoordinator) SlotReady(slotNum int) bool {
+	lastUsed, ok := mc.slotLastUsed[slotNum]
+	if mc.config == nil {
+		return true
+	}
+
+	mc.config.mu.RLock()
+	cooldown := mc.config.SlotCooldowns[slotNum]
+	mc.config.mu.RUnlock()
+
+	timerReady := !ok || time.Since(lastUsed) >= time.Duration(cooldown)*time.Millisecond
+
+	cooling, known := mc.barCooling(slotNum, lastUsed)
+	switch {
+	case !known:
+		return timerReady
+	case cooling && timerReady:
+		LogDebug("Slot %d still cooling down on the action bar, timer expired early", slotNum)
 		return false
+	case !cooling && !timerReady:
+		LogDebug("Slot %d ready on the action bar, clearing its cooldown timer", slotNum)
+		delete(mc.slotLastUsed, slotNum)
+		return true
+	default:
+		return timerReady
+	}
+}
+
+// barCooling reads the cooldown sweep of a slot's action bar icon. known is
+// false when the bar is not read, shows another skill bar page, or was last
+// read too soon after the slot was pressed at lastUsed.
+func (mc *MovementCoordinator) barCooling(slotNum int, lastUsed time.Time) (cooling, known bool) {
+	if mc.stats == nil {
+		return false, false
+	}
+
+	mc.config.mu.RLock()
+	enabled := mc.config.ReadActionBarCooldowns
+	mc.config.mu.RUnlock()
+	if !enabled {
+		return false, false
+	}
+
+	page, slot := SlotPage(slotNum)
+	if page != mc.showingPage() {
+		return false, false
+	}
+
+	cooling, readAt := mc.stats.GetSlotCooling(slot)
+	if readAt.IsZero() || readAt.Before(lastUsed.Add(actionBarReadDelay)) {
+		return false, false
 	}
+	return cooling, true
+}
 
-	// Use first available slot
-	// In a full implementation, you'd track cooldowns
-	slot := slots[0]
-	mc.UseSlot(slot)
+// SetScreenInfo sets the screen DragRotate drags on
+func (mc *MovementCoordinator) SetScreenInfo(screenInfo *ScreenInfo) {
+	mc.screenInfo = screenInfo
+}
+
+// SetClientStats sets where the current FP (SlotFPCost) and chat focus are read from
+func (mc *MovementCoordinator) SetClientStats(stats *ClientStats) {
+	mc.stats = stats
+}
+
+// hasFPFor reports whether the current FP covers the slot's SlotFPCost.
+// Slots without a cost, or an unknown FP, always pass.
+func (mc *MovementCoordinator) hasFPFor(slotNum int) bool {
+	if mc.config == nil || mc.stats == nil {
+		return true
+	}
+
+	mc.config.mu.RLock()
+	cost := mc.config.SlotFPCost[slotNum]
+	mc.config.mu.RUnlock()
+	if cost <= 0 {
+		return true
+	}
+
+	fp := mc.stats.GetFPPercent()
+	if fp >= cost {
+		return true
+	}
+	LogDebug("Slot %d needs %d%% FP, only %d%% left, skipping", slotNum, cost, fp)
+	mc.fpShortage = true
+	return false
+}
+
+// LastSlotUse returns when any of slots was last used (zero if none was used yet)
+func (mc *MovementCoordinator) LastSlotUse(slots []int) time.Time {
+	var last time.Time
+	for _, slotNum := range slots {
+		if used := mc.slotLastUsed[slotNum]; used.After(last) {
+			last = used
+		}
+	}
+	return last
+}
+
+// TakeFPShortage reports whether a slot was skipped for low FP since the last
+// call, so FP can be restored before the skill is tried again
+func (mc *MovementCoordinator) TakeFPShortage() bool {
+	shortage := mc.fpShortage
+	mc.fpShortage = false
+	return shortage
+}
+
+// TryUseSlot uses a slot unless it is still cooling down or costs more FP than left
+// Returns true if the slot was pressed
+func (mc *MovementCoordinator) TryUseSlot(slotNum int) bool {
+	if !mc.SlotReady(slotNum) {
+		LogDebug("Slot %d on cooldown, skipping", slotNum)
+		return false
+	}
+	if !mc.hasFPFor(slotNum) {
+		return false
+	}
+	mc.UseSlot(slotNum)
 	return true
 }
 
+// UseSkill uses the first slot of the list that is not cooling down and has enough FP
+// Returns the slot that was pressed, or -1 if all slots are on cooldown
+func (mc *MovementCoordinator) UseSkill(slots []int) int {
+	for _, slot := range slots {
+		if mc.TryUseSlot(slot) {
+			return slot
+		}
+	}
+	return -1
+}
+
 // LockTarget locks onto current target (Z key)
 func (mc *MovementCoordinator) LockTarget() {
 	LogDebug("Locking target")
@@ -328,6 +544,69 @@ func (mc *MovementCoordinator) SelectPartyLeader(screenInfo *ScreenInfo) {
 	mc.Wait(500 * time.Millisecond)
 }
 
+// statPlusButtons holds the base (800x600) positions of the stat "+" buttons
+// in the character window
+var statPlusButtons = map[string]Point{
+	"STR": {X: 152, Y: 318},
+	"STA": {X: 152, Y: 334},
+	"DEX": {X: 152, Y: 350},
+	"INT": {X: 152, Y: 366},
+}
+
+// OpenCharacterWindow toggles the character window (H key)
+func (mc *MovementCoordinator) OpenCharacterWindow() {
+	mc.PressKey("h")
+	mc.Wait(300 * time.Millisecond)
+}
+
+// AllocateStatPoints clicks the "+" button for each stat in order and applies
+func (mc *MovementCoordinator) AllocateStatPoints(screenInfo *ScreenInfo, stats []string) {
+	LogDebug("Allocating stat points: %v", stats)
+
+	mc.OpenCharacterWindow()
+
+	for _, stat := range stats {
+		button, ok := statPlusButtons[stat]
+		if !ok {
+			LogWarn("Unknown stat: %s", stat)
+			continue
+		}
+		x, y := screenInfo.Scale(button.X, button.Y)
+		mc.action.MouseClick(x, y)
+		mc.Wait(150 * time.Millisecond)
+	}
+
+	// Click apply button (scaled based on resolution)
+	applyX, applyY := screenInfo.Scale(120, 390)
+	mc.action.MouseClick(applyX, applyY)
+	mc.Wait(300 * time.Millisecond)
+
+	mc.OpenCharacterWindow()
+}
+
+// AnswerPopup clicks accept or decline on a confirmation popup (party invite, trade)
+func (mc *MovementCoordinator) AnswerPopup(screenInfo *ScreenInfo, accept bool) {
+	// Popup buttons are centered below the message (scaled based on resolution)
+	x, y := screenInfo.Scale(460, 330)
+	if accept {
+		x, y = screenInfo.Scale(340, 330)
+	}
+	mc.action.MouseClick(x, y)
+	mc.Wait(200 * time.Millisecond)
+}
+
+// AnswerDialog clicks accept or decline on a dialog located on screen,
+// with Accept at the bottom left and Decline at the bottom right
+func (mc *MovementCoordinator) AnswerDialog(dialog Bounds, accept bool) {
+	x := dialog.X + dialog.W*7/10
+	if accept {
+		x = dialog.X + dialog.W*3/10
+	}
+	y := dialog.Y + dialog.H*85/100
+	mc.action.MouseClick(x, y)
+	mc.Wait(200 * time.Millisecond)
+}
+
 // FollowTarget initiates following current target
 func (mc *MovementCoordinator) FollowTarget() {
 	mc.LockTarget() // Z key follows the target in Flyff
@@ -358,6 +637,21 @@ func (mc *MovementCoordinator) RandomCameraMovement() {
 	mc.Wait(50 * time.Millisecond)
 }
 
+// zoomOutTicks is enough wheel ticks to reach the camera's maximum zoom out from any zoom level
+const zoomOutTicks = 30
+
+// ResetZoom brings the camera to a known zoom level: fully zoomed out, then
+// zoomed in by ticks. The cursor is placed at point (usually the screen center).
+func (mc *MovementCoordinator) ResetZoom(point Point, ticks int) {
+	LogDebug("Resetting camera zoom (%d ticks in)", ticks)
+	if err := mc.action.Scroll(point.X, point.Y, zoomOutTicks); err != nil {
+		return
+	}
+	mc.Wait(100 * time.Millisecond)
+	mc.action.Scroll(point.X, point.Y, -ticks)
+	mc.Wait(100 * time.Millisecond)
+}
+
 // StopAllMovement stops all movement keys
 func (mc *MovementCoordinator) StopAllMovement() {
 	keys := []string{"w", "a", "s", "d", "space", "left", "right"}
@@ -383,9 +677,37 @@ func (mc *MovementCoordinator) TypeText(text string) {
 	}
 }
 
+// TypeTextChars types a text message one key press per character (for chat).
+// abort is checked before each character; returns false if typing was aborted.
+func (mc *MovementCoordinator) TypeTextChars(text string, abort func() bool) bool {
+	for _, char := range text {
+		if abort() {
+			return false
+		}
+		key := string(char)
+		if char == ' ' {
+			key = "space"
+		}
+		mc.action.SendKey(key, KeyPress)
+		mc.WaitRandom(30, 80)
+	}
+	if mc.browser != nil {
+		mc.browser.LogAction("Type: " + text)
+	}
+	return !abort()
+}
+
 // HoldKeyFor holds a key for a specific duration then releases it
 func (mc *MovementCoordinator) HoldKeyFor(key string, duration time.Duration) {
 	mc.HoldKey(key)
 	time.Sleep(duration)
 	mc.ReleaseKey(key)
 }
+
+// DoubleTap taps a key twice, each tap held for tap with gap between them,
+// e.g. to trigger a directional dodge
+func (mc *MovementCoordinator) DoubleTap(key string, tap, gap time.Duration) {
+	mc.HoldKeyFor(key, tap)
+	time.Sleep(gap)
+	mc.HoldKeyFor(key, tap)
+}
@@ -0,0 +1,206 @@
+// Package main - ocr.go
+//
+// This file implements mob name recognition for name-based target filtering.
+// Detected name boxes are cropped, upscaled and passed to the tesseract CLI.
+//
+// Key Responsibilities:
+//   - Text recognition of a single name box or message line (tesseract, single line mode)
+//   - Fuzzy name matching against whitelist/blacklist (Levenshtein distance)
+//   - History of recently seen names (used by the tray "Mob Filter" menu)
+//
+// Requirements:
+// The tesseract binary must be available in PATH. If it is missing, recognition
+// fails, names stay empty and name filtering keeps every mob. Names are cached
+// per nameplate position for nameCacheTTL (see MobNameHistory.Lookup).
+//
+// Matching:
+// Names are compared case-insensitively and tolerate up to 2 OCR character
+// errors, e.g. "Sma1l Mia" matches "Small Mia".
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxNameDistance is the maximum Levenshtein distance for two names to match
+const maxNameDistance = 2
+
+// RecognizeText runs OCR over a region of the image and returns the trimmed text.
+//
+// Parameters:
+//   - img: Source image
+//   - region: Region containing a single line of text (e.g. a mob name box)
+//
+// Returns:
+//   - string: Recognized text, empty if nothing was recognized
+//   - error: Encoding or tesseract execution error
+func RecognizeText(img *image.RGBA, region Bounds) (string, error) {
+	rect := image.Rect(region.X, region.Y, region.X+region.W, region.Y+region.H).Intersect(img.Bounds())
+	if rect.Empty() {
+		return "", nil
+	}
+
+	// Upscale 3x, tesseract is unreliable on small game fonts
+	const scale = 3
+	crop := image.NewRGBA(image.Rect(0, 0, rect.Dx()*scale, rect.Dy()*scale))
+	for y := 0; y < crop.Bounds().Dy(); y++ {
+		for x := 0; x < crop.Bounds().Dx(); x++ {
+			crop.SetRGBA(x, y, img.RGBAAt(rect.Min.X+x/scale, rect.Min.Y+y/scale))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, crop); err != nil {
+		return "", fmt.Errorf("failed to encode name box: %w", err)
+	}
+
+	// --psm 7: treat the image as a single text line
+	cmd := exec.Command("tesseract", "stdin", "stdout", "--psm", "7")
+	cmd.Stdin = &buf
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("tesseract failed: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// MatchesMobName reports whether name matches any entry of list
+// (case-insensitive, up to maxNameDistance character errors)
+func MatchesMobName(name string, list []string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return false
+	}
+
+	for _, entry := range list {
+		if levenshtein(name, strings.ToLower(strings.TrimSpace(entry))) <= maxNameDistance {
+			return true
+		}
+	}
+	return false
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// Recognized names are cached per nameplate position, so tesseract runs once
+// per mob instead of once per mob per frame
+const (
+	nameCacheRadius = 15              // Max nameplate center movement in pixels to reuse a name
+	nameCacheTTL    = 3 * time.Second // How long a recognized name is reused
+)
+
+// MobNameHistory keeps the most recently seen distinct mob names, and the names
+// recognized at recent nameplate positions (thread-safe)
+type MobNameHistory struct {
+	names  []string
+	limit  int
+	cached []cachedName
+	mu     sync.RWMutex
+}
+
+// cachedName is a name recognized at a nameplate position
+type cachedName struct {
+	center Point
+	name   string
+	seen   time.Time
+}
+
+// NewMobNameHistory creates a history holding up to limit names
+func NewMobNameHistory(limit int) *MobNameHistory {
+	return &MobNameHistory{
+		names: make([]string, 0, limit),
+		limit: limit,
+	}
+}
+
+// Add records a name, moving it to the front if it was already known
+func (h *MobNameHistory) Add(name string) {
+	if name == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, known := range h.names {
+		if strings.EqualFold(known, name) {
+			h.names = append(h.names[:i], h.names[i+1:]...)
+			break
+		}
+	}
+
+	h.names = append([]string{name}, h.names...)
+	if len(h.names) > h.limit {
+		h.names = h.names[:h.limit]
+	}
+}
+
+// Names returns the recent names, most recent first
+func (h *MobNameHistory) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	names := make([]string, len(h.names))
+	copy(names, h.names)
+	return names
+}
+
+// Lookup returns the name recognized for a nameplate at bounds within the
+// last nameCacheTTL ("" is a valid cached result: nothing was recognized)
+func (h *MobNameHistory) Lookup(bounds Bounds) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	center := bounds.Center()
+	for _, entry := range h.cached {
+		if time.Since(entry.seen) <= nameCacheTTL && entry.center.Distance(center) <= nameCacheRadius {
+			return entry.name, true
+		}
+	}
+	return "", false
+}
+
+// Remember caches the name recognized for a nameplate at bounds
+func (h *MobNameHistory) Remember(bounds Bounds, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	active := h.cached[:0]
+	for _, entry := range h.cached {
+		if time.Since(entry.seen) <= nameCacheTTL {
+			active = append(active, entry)
+		}
+	}
+	h.cached = append(active, cachedName{center: bounds.Center(), name: name, seen: time.Now()})
+}
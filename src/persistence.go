@@ -5,11 +5,12 @@
 //
 // Persistent Data:
 //   - Bot Configuration: Mode, skill slots, thresholds, mob colors, behavior settings
-//   - Browser Cookies: Session cookies from universe.flyff.com for automatic login
+//   - Browser Cookies: Session cookies from the game server (Config.GameURL) for automatic login
 //
 // File Format:
 // JSON with 2-space indentation for readability. Example structure:
 // {
+//   "schemaVersion": 1,
 //   "config": {
 //     "Mode": "Farming",
 //     "AttackSlots": [0],
@@ -37,6 +38,12 @@
 //   - If file doesn't exist: Use default configuration, empty cookies
 //   - If file is corrupted: Log error, use defaults
 //
+// Schema Versions:
+// data.json stores the schemaVersion it was written with. Files older than
+// schemaVersion run through dataMigrations one version at a time (renamed
+// fields etc.) and are written back. Fields missing from any file keep their
+// NewConfig defaults instead of becoming zero.
+//
 // Error Handling:
 // Load errors are logged but do not prevent bot startup. The bot falls back
 // to default configuration and continues running.
@@ -44,11 +51,105 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"reflect"
+	"strings"
 )
 
 const dataFile = "data.json"
 
+// schemaVersion is the current data.json layout version, one more than the
+// number of dataMigrations
+const schemaVersion = 1
+
+// schemaMigration upgrades the config of a data file by one schema version
+type schemaMigration struct {
+	description string
+	apply       func(config map[string]json.RawMessage)
+}
+
+// dataMigrations upgrade data.json configs, index N upgrades vN to vN+1.
+// Files without schemaVersion are v0.
+var dataMigrations = []schemaMigration{
+	{"rename FinisherTargetHP to FinisherHPThreshold", func(config map[string]json.RawMessage) {
+		renameKey(config, "FinisherTargetHP", "FinisherHPThreshold")
+	}},
+}
+
+// renameKey moves the value of key from to key to, unless to is already set
+func renameKey(config map[string]json.RawMessage, from, to string) {
+	value, ok := config[from]
+	if !ok {
+		return
+	}
+	delete(config, from)
+	if _, exists := config[to]; !exists {
+		config[to] = value
+	}
+}
+
+// migrateData upgrades the data file content to schemaVersion.
+// Returns the upgraded content and the version the content was written with.
+func migrateData(content []byte) ([]byte, int, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	version := 0
+	if raw, ok := doc["schemaVersion"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, 0, fmt.Errorf("invalid schemaVersion: %w", err)
+		}
+	}
+	if version >= schemaVersion {
+		return content, version, nil
+	}
+
+	config := make(map[string]json.RawMessage)
+	if raw, ok := doc["config"]; ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, 0, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	for v := version; v < schemaVersion; v++ {
+		LogInfo("Migrating %s v%d -> v%d: %s", dataFile, v, v+1, dataMigrations[v].description)
+		dataMigrations[v].apply(config)
+	}
+
+	var err error
+	if doc["config"], err = json.Marshal(config); err != nil {
+		return nil, 0, err
+	}
+	if doc["schemaVersion"], err = json.Marshal(schemaVersion); err != nil {
+		return nil, 0, err
+	}
+	migrated, err := json.Marshal(doc)
+	return migrated, version, err
+}
+
+// clearSavedMaps sets the map fields of config to nil when saved (the "config"
+// object of data.json) has them. encoding/json adds decoded keys to an existing
+// map instead of replacing it, so keys of a non-empty default map (e.g.
+// StatDistribution) would come back on every load. Maps missing from the file
+// keep their defaults.
+func clearSavedMaps(config *Config, saved map[string]json.RawMessage) {
+	value := reflect.ValueOf(config).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Type.Kind() != reflect.Map || !field.IsExported() {
+			continue
+		}
+		for key := range saved {
+			if strings.EqualFold(key, field.Name) {
+				value.Field(i).Set(reflect.Zero(field.Type))
+				break
+			}
+		}
+	}
+}
+
 // SaveData saves configuration and cookies to data.json.
 //
 // Creates or overwrites the data file with current bot state. Uses JSON encoding
@@ -96,11 +197,11 @@ func SaveData(data *PersistentData) error {
 // Load Algorithm:
 //   1. Check if data.json exists
 //      - If not: Return new default configuration
-//   2. Open file for reading
-//   3. Create JSON decoder
-//   4. Decode into PersistentData structure
+//   2. Read the file
+//   3. Migrate it to schemaVersion (see migrateData)
+//   4. Decode over a default PersistentData, so missing fields keep defaults
 //      - If decode fails: Log error, return defaults
-//   5. Close file handle (deferred)
+//   5. Write a migrated file back
 //   6. Log success message
 //
 // Error Recovery:
@@ -113,20 +214,39 @@ func LoadData() (*PersistentData, error) {
 		return NewPersistentData(), nil
 	}
 
-	file, err := os.Open(dataFile)
+	content, err := os.ReadFile(dataFile)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	var data PersistentData
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&data)
+	migrated, version, err := migrateData(content)
 	if err != nil {
 		LogError("Failed to decode data file: %v", err)
 		return NewPersistentData(), nil
 	}
+	if version > schemaVersion {
+		LogWarn("%s has schema v%d, newer than this version supports (v%d), unknown fields are ignored", dataFile, version, schemaVersion)
+	}
+
+	data := NewPersistentData()
+	var doc struct {
+		Config map[string]json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal(migrated, &doc); err == nil {
+		clearSavedMaps(data.Config, doc.Config)
+	}
+	if err := json.Unmarshal(migrated, data); err != nil {
+		LogError("Failed to decode data file: %v", err)
+		return NewPersistentData(), nil
+	}
+
+	if version < schemaVersion {
+		data.SchemaVersion = schemaVersion
+		if err := SaveData(data); err != nil {
+			LogError("Failed to save migrated data file: %v", err)
+		}
+	}
 
 	LogInfo("Data loaded from %s", dataFile)
-	return &data, nil
+	return data, nil
 }
@@ -0,0 +1,894 @@
+// Package main - pixelscan.go
+//
+// Pixel scanning detections of ImageAnalyzer that need no OpenCV, shared by
+// the OpenCV and the no-OpenCV (-tags nocv) builds:
+//   - Mob name detection (passive/aggressive/violet) via HSV name colors
+//   - Target marker detection (red/blue) and target distance estimation
+//   - Level up text, drop labels and other players' nameplates
+//   - Party member HP bar detection (under the minimap)
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// IdentifyMobs identifies all mobs in the current image. A static frame (see
+// SetFrame) returns the mobs of the last processed frame.
+func (ia *ImageAnalyzer) IdentifyMobs(config *Config) []Target {
+	if !ia.IsStaticFrame() {
+		mobs := ia.identifyMobs(config)
+		ia.mu.Lock()
+		ia.lastMobs = mobs
+		ia.mu.Unlock()
+	}
+
+	// Copy, callers sort and filter the result
+	ia.mu.RLock()
+	defer ia.mu.RUnlock()
+	return append([]Target(nil), ia.lastMobs...)
+}
+
+// identifyMobs detects the mobs of the current image
+func (ia *ImageAnalyzer) identifyMobs(config *Config) []Target {
+	img := ia.GetImage()
+	if img == nil {
+		return nil
+	}
+
+	// Scan region - expanded to catch more mobs, filter in post-processing
+	// Changed from Y:60 to Y:0 to match Rust version's full-screen scan
+	region := Bounds{
+		X: 0,
+		Y: 0, // Start from top (was 60)
+		W: ia.screenInfo.Width,
+		H: ia.screenInfo.Height - 100, // Reduced from 170 to 100
+	}
+
+	// Name color ranges can be edited from the tray at runtime and are
+	// overridden by the map profile
+	detection := config.Detection()
+	mobColors := detection.MobColors
+	config.mu.RLock()
+	detectMode := config.MobDetectMode
+	keepViolet := config.PrioritizeViolet
+	config.mu.RUnlock()
+
+	if detectMode == MobDetectTemplate {
+		return ia.classifyLevelBands(img, ia.identifyMobsByTemplate(img, region, config), config)
+	}
+
+	// Detect passive mobs (yellow names)
+	passivePoints := ia.scanPixelsForHSV(img, region, mobColors.Passive)
+
+	// Detect aggressive mobs (red names)
+	aggressivePoints := ia.scanPixelsForHSV(img, region, mobColors.Aggressive)
+
+	// Detect violet mobs (purple names)
+	violetPoints := ia.scanPixelsForHSV(img, region, mobColors.Violet)
+
+	LogDebug("Found %d passive points, %d aggressive points, %d violet points",
+		len(passivePoints), len(aggressivePoints), len(violetPoints))
+
+	// Cluster points into mobs
+	var mobs []Target
+
+	// Process passive mobs
+	passiveClusters := clusterPoints(passivePoints, 50, 3)
+	LogDebug("Passive clustering: %d points -> %d clusters", len(passivePoints), len(passiveClusters))
+	for _, bounds := range passiveClusters {
+		// Filter: width check + avoid HP bar region (y < MobMinY)
+		// Matching Rust logic (image_analyzer.rs:164-166): w > min && w < max
+		if bounds.W > detection.MinMobNameWidth && bounds.W < detection.MaxMobNameWidth && bounds.Y >= detection.MobMinY {
+			LogDebug("Passive mob ACCEPTED at (%d,%d) size %dx%d", bounds.X, bounds.Y, bounds.W, bounds.H)
+			mobs = append(mobs, Target{
+				Type:   MobPassive,
+				Bounds: bounds,
+			})
+		} else {
+			LogDebug("Passive cluster REJECTED at (%d,%d) size %dx%d (width must be >%d and <%d, y: %d)",
+				bounds.X, bounds.Y, bounds.W, bounds.H, detection.MinMobNameWidth, detection.MaxMobNameWidth, bounds.Y)
+		}
+	}
+
+	// Process aggressive mobs
+	aggressiveClusters := clusterPoints(aggressivePoints, 50, 3)
+	LogDebug("Aggressive clustering: %d points -> %d clusters", len(aggressivePoints), len(aggressiveClusters))
+	for _, bounds := range aggressiveClusters {
+		// Filter: width check + avoid HP bar region (y < MobMinY)
+		// Matching Rust logic (image_analyzer.rs:164-166): w > min && w < max
+		if bounds.W > detection.MinMobNameWidth && bounds.W < detection.MaxMobNameWidth && bounds.Y >= detection.MobMinY {
+			LogDebug("Aggressive mob ACCEPTED at (%d,%d) size %dx%d", bounds.X, bounds.Y, bounds.W, bounds.H)
+			mobs = append(mobs, Target{
+				Type:   MobAggressive,
+				Bounds: bounds,
+			})
+		} else {
+			LogDebug("Aggressive cluster REJECTED at (%d,%d) size %dx%d (width must be >%d and <%d, y: %d)",
+				bounds.X, bounds.Y, bounds.W, bounds.H, detection.MinMobNameWidth, detection.MaxMobNameWidth, bounds.Y)
+		}
+	}
+
+	// Violet mobs are filtered out unless PrioritizeViolet is set
+	if len(violetPoints) > 0 {
+		violetClusters := clusterPoints(violetPoints, 50, 3)
+		for _, bounds := range violetClusters {
+			// Matching Rust logic: w > min && w < max
+			if bounds.W > detection.MinMobNameWidth && bounds.W < detection.MaxMobNameWidth {
+				if !keepViolet {
+					LogDebug("Detected violet mob at (%d,%d), filtering out", bounds.X, bounds.Y)
+					continue
+				}
+				LogDebug("Violet mob ACCEPTED at (%d,%d) size %dx%d", bounds.X, bounds.Y, bounds.W, bounds.H)
+				mobs = append(mobs, Target{
+					Type:   MobViolet,
+					Bounds: bounds,
+				})
+			}
+		}
+	}
+
+	LogDebug("Identified %d total mobs (passive clusters: %d, aggressive clusters: %d)",
+		len(mobs), len(passiveClusters), len(aggressiveClusters))
+
+	return ia.classifyLevelBands(img, ia.filterMobsByName(img, mobs, config), config)
+}
+
+// classifyLevelBands sets Target.LevelBand of each mob from its name tint
+func (ia *ImageAnalyzer) classifyLevelBands(img *image.RGBA, mobs []Target, config *Config) []Target {
+	config.mu.RLock()
+	bands := config.LevelBandColors
+	config.mu.RUnlock()
+	colors := config.Detection().MobColors
+
+	for i := range mobs {
+		regular := colors.Passive
+		if mobs[i].Type == MobAggressive {
+			regular = colors.Aggressive
+		}
+
+		// Same as detectNameColorAt: the band with the most matching pixels wins
+		classes := []struct {
+			band string
+			hsv  HSVBounds
+		}{
+			{LevelBandLower, bands.Lower},
+			{LevelBandHigher, bands.Higher},
+			{LevelBandSame, regular},
+		}
+
+		best := LevelBandUnknown
+		bestCount := minNameColorPixels - 1
+		for _, class := range classes {
+			count := len(ia.scanPixelsForHSV(img, mobs[i].Bounds, class.hsv))
+			if count > bestCount {
+				best = class.band
+				bestCount = count
+			}
+		}
+		mobs[i].LevelBand = best
+	}
+	return mobs
+}
+
+// filterMobsByName reads mob names via OCR and applies the whitelist/blacklist.
+// If name reading is disabled and both lists are empty, mobs are returned unchanged.
+func (ia *ImageAnalyzer) filterMobsByName(img *image.RGBA, mobs []Target, config *Config) []Target {
+	config.mu.RLock()
+	readNames := config.MobNameOCR
+	whitelist := config.MobWhitelist
+	blacklist := config.MobBlacklist
+	config.mu.RUnlock()
+
+	if !readNames && len(whitelist) == 0 && len(blacklist) == 0 {
+		return mobs
+	}
+
+	filtered := make([]Target, 0, len(mobs))
+	for _, mob := range mobs {
+		name, cached := ia.mobNames.Lookup(mob.Bounds)
+		if !cached {
+			var err error
+			name, err = RecognizeText(img, mob.Bounds.Grow(2))
+			if err != nil {
+				LogDebug("Mob name OCR failed at (%d,%d): %v", mob.Bounds.X, mob.Bounds.Y, err)
+			}
+			ia.mobNames.Remember(mob.Bounds, name)
+		}
+		mob.Name = name
+		ia.mobNames.Add(name)
+
+		// Unreadable names (tesseract missing or failing) are never filtered out
+		if name == "" {
+			filtered = append(filtered, mob)
+			continue
+		}
+		if len(whitelist) > 0 && !MatchesMobName(name, whitelist) {
+			LogDebug("Mob %q not in whitelist, skipping", name)
+			continue
+		}
+		if len(blacklist) > 0 && MatchesMobName(name, blacklist) {
+			LogDebug("Mob %q in blacklist, skipping", name)
+			continue
+		}
+		filtered = append(filtered, mob)
+	}
+
+	return filtered
+}
+
+// DetectTargetMarker detects the target marker above selected target
+func (ia *ImageAnalyzer) DetectTargetMarker() bool {
+	img := ia.GetImage()
+	if img == nil {
+		return false
+	}
+
+	// Search in upper-middle area of screen
+	region := Bounds{
+		X: ia.screenInfo.Width / 4,
+		Y: ia.screenInfo.Height / 6,
+		W: ia.screenInfo.Width / 2,
+		H: ia.screenInfo.Height / 3,
+	}
+
+	// Try blue marker first (for Azria and other zones)
+	blueMarkerColors := []Color{
+		NewColor(131, 148, 205),
+	}
+	bluePoints := ia.scanPixelsForColors(img, region, blueMarkerColors, 5)
+
+	if len(bluePoints) > 20 {
+		LogDebug("Blue target marker detected (%d points)", len(bluePoints))
+		return true
+	}
+
+	// Fallback to red marker (normal zones)
+	redMarkerColors := []Color{
+		NewColor(246, 90, 106),
+	}
+	redPoints := ia.scanPixelsForColors(img, region, redMarkerColors, 5)
+
+	if len(redPoints) > 20 {
+		LogDebug("Red target marker detected (%d points)", len(redPoints))
+		return true
+	}
+
+	return false
+}
+
+// DetectLevelUp detects the golden "Level Up!" text shown on level up
+func (ia *ImageAnalyzer) DetectLevelUp() bool {
+	img := ia.GetImage()
+	if img == nil {
+		return false
+	}
+
+	// Text is rendered above the character in the upper-middle area
+	region := Bounds{
+		X: ia.screenInfo.Width / 4,
+		Y: ia.screenInfo.Height / 6,
+		W: ia.screenInfo.Width / 2,
+		H: ia.screenInfo.Height / 4,
+	}
+
+	levelUpColors := []Color{
+		NewColor(255, 220, 90),
+		NewColor(250, 190, 40),
+	}
+	points := ia.scanPixelsForColors(img, region, levelUpColors, 10)
+
+	if len(points) > 300 {
+		LogDebug("Level up text detected (%d points)", len(points))
+		return true
+	}
+
+	return false
+}
+
+// outOfRangeMinPoints is the minimum number of red text pixels before the
+// "out of range" message region is read with OCR
+const outOfRangeMinPoints = 60
+
+// DetectOutOfRange detects the "target out of range" message, by template if
+// the game language has one, otherwise by reading the red message text with OCR.
+// region is given on the 800x600 base resolution.
+func (ia *ImageAnalyzer) DetectOutOfRange(region Bounds) bool {
+	img := ia.GetImage()
+	if img == nil || region.W <= 0 || region.H <= 0 {
+		return false
+	}
+
+	// Template of the game language's message, if messages.json has one
+	if matched, ok := ia.detector.MatchMessage(img, region, MessageOutOfRange); ok {
+		if matched {
+			LogDebug("Out of range message matched")
+		}
+		return matched
+	}
+
+	// Red text is only a cheap check before OCR, other red UI and damage
+	// numbers show up in the region too
+	messageColors := []Color{
+		NewColor(255, 60, 60),
+		NewColor(220, 30, 30),
+	}
+	scaled := ia.screenInfo.ScaleBounds(region)
+	points := ia.scanPixelsForColors(img, scaled, messageColors, 25)
+	if len(points) < outOfRangeMinPoints {
+		return false
+	}
+
+	text, err := RecognizeText(img, scaled)
+	if err != nil {
+		LogDebug("Out of range message OCR failed: %v", err)
+		return false
+	}
+	if !gameMessages.ContainsText(text, MessageOutOfRange) {
+		return false
+	}
+
+	LogDebug("Out of range message detected: %q", text)
+	return true
+}
+
+// Drop label detection
+const (
+	dropSearchWidth = 300 // Width of the ground area searched around the dead mob
+	dropSearchAbove = 40  // Pixels searched above the dead mob position
+	dropSearchBelow = 120 // Pixels searched below the dead mob position
+	dropMinLabelW   = 12  // Min item label width (smaller clusters are sparkles/noise)
+	dropMaxLabelH   = 20  // Max item label height (taller clusters are UI/character parts)
+	characterHalfW  = 40  // Half width of the character model around the screen center
+	characterHeight = 120 // Height of the character model above the screen center
+)
+
+// DetectDrops counts item labels (white text) on the ground around a dead mob.
+//
+// Returns the label count and whether the search area overlaps the character
+// model, which can hide labels dropped right under the player (melee kills).
+// A count of 0 with occluded set means drops may still be there.
+func (ia *ImageAnalyzer) DetectDrops(deadAt Point) (int, bool) {
+	img := ia.GetImage()
+	if img == nil {
+		return 0, false
+	}
+
+	region := Bounds{
+		X: deadAt.X - dropSearchWidth/2,
+		Y: deadAt.Y - dropSearchAbove,
+		W: dropSearchWidth,
+		H: dropSearchAbove + dropSearchBelow,
+	}
+
+	center := ia.screenInfo.Center()
+	character := Bounds{
+		X: center.X - characterHalfW,
+		Y: center.Y - characterHeight,
+		W: characterHalfW * 2,
+		H: characterHeight,
+	}
+	occluded := boundsOverlap(region, character)
+
+	points := ia.scanPixelsForColors(img, region, []Color{NewColor(255, 255, 255)}, 15)
+	drops := 0
+	for _, cluster := range clusterPoints(points, 4, 3) {
+		if cluster.W >= dropMinLabelW && cluster.H <= dropMaxLabelH {
+			drops++
+		}
+	}
+
+	LogDebug("Detected %d drop labels around (%d,%d) (character overlap: %v)", drops, deadAt.X, deadAt.Y, occluded)
+	return drops, occluded
+}
+
+// DetectTargetDistance calculates distance to target marker
+func (ia *ImageAnalyzer) DetectTargetDistance() int {
+	marker := ia.DetectTargetMarkerPosition()
+	if marker == nil {
+		return 9999
+	}
+
+	// Calculate distance from screen center
+	return int(marker.Distance(ia.screenInfo.Center()))
+}
+
+// Target distance estimation (see EstimateTargetDistance)
+const (
+	nameplateMarkerRadius = 60 // Max distance in base pixels between the target marker and the target's nameplate
+	minNameplateHeight    = 4  // Nameplates lower than this (base pixels) are too small to estimate from
+)
+
+// EstimateTargetDistance estimates the distance to the selected target in
+// TargetDistance units (pixels from screen center on the 800x600 base
+// resolution), calibrated by the Config.DistanceRef* settings:
+//   - nameplate height: a nameplate DistanceRefNameplateH tall is at
+//     DistanceRefDistance, the distance grows as the nameplate shrinks
+//   - marker height: a marker at DistanceRefMarkerY is at DistanceRefDistance,
+//     the distance grows linearly toward the top of the screen
+//
+// The available estimates are averaged. Returns 9999 without a target marker.
+// target is the clicked mob: only a window around the marker is scanned for a
+// nameplate of its name color, nil skips the nameplate estimate.
+func (ia *ImageAnalyzer) EstimateTargetDistance(config *Config, target *Target) int {
+	marker := ia.DetectTargetMarkerPosition()
+	if marker == nil {
+		return 9999
+	}
+
+	config.mu.RLock()
+	refDistance := config.DistanceRefDistance
+	refNameplateH := config.DistanceRefNameplateH
+	refMarkerY := config.DistanceRefMarkerY
+	config.mu.RUnlock()
+
+	scaleX := 800.0 / float64(ia.screenInfo.Width)
+	scaleY := 600.0 / float64(ia.screenInfo.Height)
+	markerX := float64(marker.X) * scaleX
+	markerY := float64(marker.Y) * scaleY
+
+	var estimates []float64
+
+	if refNameplateH > 0 && target != nil {
+		if nameplate := ia.findNameplateNear(*marker, target.Type, config); nameplate != nil {
+			if h := float64(nameplate.H) * scaleY; h >= minNameplateHeight {
+				estimates = append(estimates, float64(refDistance)*float64(refNameplateH)/h)
+			}
+		}
+	}
+
+	// The character stands at the center of the 800x600 base screen
+	if refMarkerY > 0 && refMarkerY < 300 {
+		estimates = append(estimates, math.Max(0, float64(refDistance)*(300-markerY)/float64(300-refMarkerY)))
+	}
+
+	if len(estimates) == 0 {
+		// Nothing calibrated: marker distance from screen center
+		center := ia.screenInfo.Center()
+		return int(math.Hypot(markerX-float64(center.X)*scaleX, markerY-float64(center.Y)*scaleY))
+	}
+
+	var sum float64
+	for _, e := range estimates {
+		sum += e
+	}
+	return int(sum / float64(len(estimates)))
+}
+
+// minNameColorPixels is the minimum number of matching pixels to classify a name color
+const minNameColorPixels = 5
+
+// detectNameColorAt classifies the name text inside bounds by its dominant color
+func (ia *ImageAnalyzer) detectNameColorAt(bounds Bounds, config *Config) NameColor {
+	img := ia.GetImage()
+	if img == nil {
+		return NameColorUnknown
+	}
+
+	colors := config.Detection().MobColors
+
+	// Check NPC before aggressive: violet and aggressive ranges overlap in hue
+	classes := []struct {
+		color NameColor
+		hsv   HSVBounds
+	}{
+		{NameColorNPC, colors.NPC},
+		{NameColorViolet, colors.Violet},
+		{NameColorAggressive, colors.Aggressive},
+		{NameColorPassive, colors.Passive},
+	}
+
+	best := NameColorUnknown
+	bestCount := minNameColorPixels - 1
+	for _, class := range classes {
+		count := len(ia.scanPixelsForHSV(img, bounds, class.hsv))
+		if count > bestCount {
+			best = class.color
+			bestCount = count
+		}
+	}
+	return best
+}
+
+// findNameplateNear returns the nameplate of mobType's name color closest to
+// marker, within nameplateMarkerRadius (nil if none). Only the window around
+// the marker is scanned, not the whole screen.
+func (ia *ImageAnalyzer) findNameplateNear(marker Point, mobType MobType, config *Config) *Bounds {
+	img := ia.GetImage()
+	if img == nil {
+		return nil
+	}
+
+	detection := config.Detection()
+	colors := detection.MobColors
+
+	nameColor := colors.Passive
+	switch mobType {
+	case MobAggressive:
+		nameColor = colors.Aggressive
+	case MobViolet:
+		nameColor = colors.Violet
+	}
+
+	radiusX, radiusY := ia.screenInfo.Scale(nameplateMarkerRadius, nameplateMarkerRadius)
+	region := Bounds{X: marker.X - radiusX, Y: marker.Y - radiusY, W: 2 * radiusX, H: 2 * radiusY}
+
+	var nameplate *Bounds
+	best := math.Inf(1)
+	for _, bounds := range clusterPoints(ia.scanPixelsForHSV(img, region, nameColor), 50, 3) {
+		if bounds.W <= detection.MinMobNameWidth {
+			continue
+		}
+		if d := bounds.Center().Distance(marker); d <= float64(max(radiusX, radiusY)) && d < best {
+			best = d
+			found := bounds
+			nameplate = &found
+		}
+	}
+	return nameplate
+}
+
+// ownNameplateRadius is the distance in base pixels from the screen center
+// within which the nearest player nameplate is taken as our own
+const ownNameplateRadius = 100
+
+// DetectPlayerNameplates returns the nameplates of other players, found by
+// Config.PlayerNameColor with the same size filter as mob nameplates. Our own
+// nameplate (nearest the screen center) is skipped, and while a party is shown
+// so are nameplates read as Config.PartyMemberNames or Config.AutoAcceptPartyFrom.
+func (ia *ImageAnalyzer) DetectPlayerNameplates(config *Config) []Bounds {
+	img := ia.GetImage()
+	if img == nil {
+		return nil
+	}
+
+	detection := config.Detection()
+	config.mu.RLock()
+	nameColor := config.PlayerNameColor
+	partyNames := append([]string(nil), config.PartyMemberNames...)
+	if config.AutoAcceptPartyFrom != "" {
+		partyNames = append(partyNames, config.AutoAcceptPartyFrom)
+	}
+	config.mu.RUnlock()
+
+	region := Bounds{
+		X: 0,
+		Y: 0,
+		W: ia.screenInfo.Width,
+		H: ia.screenInfo.Height - 100,
+	}
+
+	var nameplates []Bounds
+	for _, bounds := range clusterPoints(ia.scanPixelsForHSV(img, region, nameColor), 50, 3) {
+		if bounds.W > detection.MinMobNameWidth && bounds.W < detection.MaxMobNameWidth && bounds.Y >= detection.MobMinY {
+			nameplates = append(nameplates, bounds)
+		}
+	}
+
+	// Our own nameplate floats above the character at the screen center
+	center := ia.screenInfo.Center()
+	ownRadius, _ := ia.screenInfo.Scale(ownNameplateRadius, 0)
+	own := -1
+	best := float64(ownRadius)
+	for i, bounds := range nameplates {
+		if distance := bounds.Center().Distance(center); distance <= best {
+			own = i
+			best = distance
+		}
+	}
+
+	// Names are only read while a party is shown, OCR is slow
+	checkParty := len(partyNames) > 0 && len(ia.DetectPartyMembers()) > 0
+
+	players := make([]Bounds, 0, len(nameplates))
+	for i, bounds := range nameplates {
+		if i == own {
+			continue
+		}
+		if checkParty {
+			name, err := RecognizeText(img, bounds.Grow(2))
+			if err != nil {
+				LogDebug("Player name OCR failed at (%d,%d): %v", bounds.X, bounds.Y, err)
+			}
+			if MatchesMobName(name, partyNames) {
+				LogDebug("Player %q is a party member, not a competitor", name)
+				continue
+			}
+		}
+		players = append(players, bounds)
+	}
+
+	LogDebug("Detected %d other player nameplates", len(players))
+	return players
+}
+
+// DetectTargetMarkerPosition returns the center of the target marker, nil if not found
+func (ia *ImageAnalyzer) DetectTargetMarkerPosition() *Point {
+	img := ia.GetImage()
+	if img == nil {
+		return nil
+	}
+
+	// Search for target marker
+	region := Bounds{
+		X: ia.screenInfo.Width / 4,
+		Y: ia.screenInfo.Height / 6,
+		W: ia.screenInfo.Width / 2,
+		H: ia.screenInfo.Height / 3,
+	}
+
+	// Try both colors
+	bluePoints := ia.scanPixelsForColors(img, region, []Color{NewColor(131, 148, 205)}, 5)
+	redPoints := ia.scanPixelsForColors(img, region, []Color{NewColor(246, 90, 106)}, 5)
+
+	var markerPoints []Point
+	if len(bluePoints) > len(redPoints) {
+		markerPoints = bluePoints
+	} else {
+		markerPoints = redPoints
+	}
+
+	if len(markerPoints) == 0 {
+		return nil
+	}
+
+	// Calculate center of marker
+	center := pointsToBounds(markerPoints).Center()
+	return &center
+}
+
+// partyBarFullWidth is the width of a full party member HP bar (800x600 base)
+const partyBarFullWidth = 100
+
+// DetectPartyMembers detects the stacked party member HP bars under the minimap.
+// Members are returned top to bottom; an empty result means no party is shown.
+func (ia *ImageAnalyzer) DetectPartyMembers() []PartyMember {
+	img := ia.GetImage()
+	if img == nil {
+		return nil
+	}
+
+	// Party list sits on the right side, below the minimap
+	region := ia.screenInfo.ScaleBounds(Bounds{X: 620, Y: 170, W: 170, H: 250})
+	points := ia.scanPixelsForColors(img, region, getStatusBarColors(StatusBarHP), 5)
+	if len(points) == 0 {
+		return nil
+	}
+
+	fullWidth, _ := ia.screenInfo.Scale(partyBarFullWidth, 0)
+	if fullWidth <= 0 {
+		return nil
+	}
+
+	// Bars are thin horizontal strips, keep clusters that look like one
+	var bars []Bounds
+	for _, bounds := range clusterPoints(points, 5, 2) {
+		if bounds.H <= 12 && bounds.W >= 2 && bounds.W <= fullWidth+5 {
+			bars = append(bars, bounds)
+		}
+	}
+
+	sort.Slice(bars, func(i, j int) bool {
+		return bars[i].Y < bars[j].Y
+	})
+
+	members := make([]PartyMember, 0, len(bars))
+	for i, bounds := range bars {
+		members = append(members, PartyMember{
+			Index:  i,
+			Bounds: bounds,
+			HP:     Clamp(bounds.W*100/fullWidth, 0, 100),
+		})
+	}
+
+	LogDebug("Detected %d party members", len(members))
+	return members
+}
+
+// scanPixelsForColors scans a region for pixels matching any of the given colors
+func (ia *ImageAnalyzer) scanPixelsForColors(img *image.RGBA, region Bounds, colors []Color, tolerance uint8) []Point {
+	var points []Point
+
+	bounds := img.Bounds()
+	minX := max(region.X, bounds.Min.X)
+	minY := max(region.Y, bounds.Min.Y)
+	maxX := min(region.X+region.W, bounds.Max.X)
+	maxY := min(region.Y+region.H, bounds.Max.Y)
+
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			// Skip HP bar region (matching Rust logic at line 231-233)
+			if x <= 250 && y <= 110 {
+				continue
+			}
+
+			c := img.RGBAAt(x, y)
+
+			// Check if pixel matches any target color
+			for _, targetColor := range colors {
+				if colorMatches(c, targetColor, tolerance) {
+					points = append(points, Point{X: x, Y: y})
+					break
+				}
+			}
+		}
+	}
+
+	return points
+}
+
+// scanPixelsForHSV scans a region for pixels within an HSV range
+func (ia *ImageAnalyzer) scanPixelsForHSV(img *image.RGBA, region Bounds, hsvRange HSVBounds) []Point {
+	var points []Point
+
+	bounds := img.Bounds()
+	minX := max(region.X, bounds.Min.X)
+	minY := max(region.Y, bounds.Min.Y)
+	maxX := min(region.X+region.W, bounds.Max.X)
+	maxY := min(region.Y+region.H, bounds.Max.Y)
+
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			// Skip HP bar region (same as scanPixelsForColors)
+			if x <= 250 && y <= 110 {
+				continue
+			}
+
+			c := img.RGBAAt(x, y)
+			h, s, v := rgbToHSV(c.R, c.G, c.B)
+			if hsvRange.Contains(h, s, v) {
+				points = append(points, Point{X: x, Y: y})
+			}
+		}
+	}
+
+	return points
+}
+
+// colorMatches checks if a color matches a target color within tolerance
+func colorMatches(c color.RGBA, target Color, tolerance uint8) bool {
+	// Allow pixels with alpha >= 250 to handle anti-aliasing and semi-transparent text
+	// This matches the Rust version which doesn't check alpha at all
+	if c.A < 250 {
+		return false
+	}
+
+	rDiff := abs(int(c.R) - int(target.R))
+	gDiff := abs(int(c.G) - int(target.G))
+	bDiff := abs(int(c.B) - int(target.B))
+
+	return rDiff <= int(tolerance) && gDiff <= int(tolerance) && bDiff <= int(tolerance)
+}
+
+// clusterPoints clusters nearby points into bounding boxes
+func clusterPoints(points []Point, distanceX, distanceY int) []Bounds {
+	if len(points) == 0 {
+		return nil
+	}
+
+	// CRITICAL: Sort points by X axis first (matching Rust's sorted_by at point_cloud.rs:78)
+	// Without sorting, clustering will not work correctly!
+	sortedPoints := make([]Point, len(points))
+	copy(sortedPoints, points)
+
+	// Sort by X coordinate
+	for i := 0; i < len(sortedPoints); i++ {
+		for j := i + 1; j < len(sortedPoints); j++ {
+			if sortedPoints[i].X > sortedPoints[j].X {
+				sortedPoints[i], sortedPoints[j] = sortedPoints[j], sortedPoints[i]
+			}
+		}
+	}
+
+	// First cluster by X axis
+	xClusters := make([][]Point, 0)
+	currentCluster := []Point{sortedPoints[0]}
+
+	for i := 1; i < len(sortedPoints); i++ {
+		if abs(sortedPoints[i].X-sortedPoints[i-1].X) <= distanceX {
+			currentCluster = append(currentCluster, sortedPoints[i])
+		} else {
+			xClusters = append(xClusters, currentCluster)
+			currentCluster = []Point{sortedPoints[i]}
+		}
+	}
+	xClusters = append(xClusters, currentCluster)
+
+	// Then cluster each X cluster by Y axis
+	var bounds []Bounds
+	for _, xCluster := range xClusters {
+		// Sort by Y
+		for i := 0; i < len(xCluster); i++ {
+			for j := i + 1; j < len(xCluster); j++ {
+				if xCluster[i].Y > xCluster[j].Y {
+					xCluster[i], xCluster[j] = xCluster[j], xCluster[i]
+				}
+			}
+		}
+
+		// Cluster by Y distance
+		yCluster := []Point{xCluster[0]}
+		for i := 1; i < len(xCluster); i++ {
+			if abs(xCluster[i].Y-xCluster[i-1].Y) <= distanceY {
+				yCluster = append(yCluster, xCluster[i])
+			} else {
+				bounds = append(bounds, pointsToBounds(yCluster))
+				yCluster = []Point{xCluster[i]}
+			}
+		}
+		bounds = append(bounds, pointsToBounds(yCluster))
+	}
+
+	return bounds
+}
+
+// Note: pointsToBounds is defined in data.go
+
+// FindClosestMob finds the closest mob to the screen center
+func (ia *ImageAnalyzer) FindClosestMob(mobs []Target) *Target {
+	if len(mobs) == 0 {
+		return nil
+	}
+
+	centerX := ia.screenInfo.Width / 2
+	centerY := ia.screenInfo.Height / 2
+
+	var closest *Target
+	minDistance := float64(99999)
+
+	// Maximum distance threshold matching Rust version
+	// 325px for normal farming, can be increased for circle pattern
+	maxDistance := 325.0
+
+	for i := range mobs {
+		mobX := mobs[i].Bounds.X + mobs[i].Bounds.W/2
+		mobY := mobs[i].Bounds.Y + mobs[i].Bounds.H/2
+
+		dx := float64(mobX - centerX)
+		dy := float64(mobY - centerY)
+		distance := math.Sqrt(dx*dx + dy*dy)
+
+		// Filter by max distance to avoid unreachable mobs
+		if distance > maxDistance {
+			continue
+		}
+
+		if distance < minDistance {
+			minDistance = distance
+			closest = &mobs[i]
+		}
+	}
+
+	return closest
+}
+
+// getStatusBarColors returns the color array for a status bar type
+func getStatusBarColors(kind StatusBarKind) []Color {
+	switch kind {
+	case StatusBarHP:
+		return []Color{
+			NewColor(174, 18, 55),
+			NewColor(188, 24, 62),
+			NewColor(204, 30, 70),
+			NewColor(220, 36, 78),
+		}
+	case StatusBarMP:
+		return []Color{
+			NewColor(20, 84, 196),
+			NewColor(36, 132, 220),
+			NewColor(44, 164, 228),
+			NewColor(56, 188, 232),
+		}
+	case StatusBarFP:
+		return []Color{
+			NewColor(45, 230, 29),
+			NewColor(28, 172, 28),
+			NewColor(44, 124, 52),
+			NewColor(20, 146, 20),
+		}
+	default:
+		return nil
+	}
+}
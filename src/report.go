@@ -0,0 +1,152 @@
+// Package main - report.go
+//
+// This file writes a summary of the session when the bot shuts down, from the
+// signal handler or the tray Quit item. The summary is saved to
+// Config.ReportDir twice: a .txt for reading and a .json for tooling.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportPrefix starts every report file name
+const reportPrefix = "session-"
+
+// SessionReport summarizes one run of the bot
+type SessionReport struct {
+	Start           time.Time         `json:"start"`
+	End             time.Time         `json:"end"`
+	UptimeSec       int64             `json:"uptime_sec"`
+	Kills           int               `json:"kills"`
+	KillsPerMinute  float64           `json:"kills_per_minute"`
+	KillsPerHour    float64           `json:"kills_per_hour"`
+	Mobs            []SessionMobStats `json:"mobs"`
+	Deaths          int               `json:"deaths"`
+	Disconnects     int               `json:"disconnects"`
+	StealsAbandoned int               `json:"steals_abandoned"`
+	DropsPickedUp   int               `json:"drops_picked_up"` // Drop labels seen at pickups, hidden drops are not counted
+}
+
+// SessionMobStats is the kill breakdown of one mob name in a SessionReport
+type SessionMobStats struct {
+	Name  string `json:"name"`
+	Kills int    `json:"kills"`
+	MinMs int64  `json:"min_ms"`
+	MaxMs int64  `json:"max_ms"`
+	AvgMs int64  `json:"avg_ms"`
+}
+
+// NewSessionReport collects the numbers of this run from stats
+func NewSessionReport(stats *Statistics) *SessionReport {
+	kills, kpm, kph, _, _ := stats.GetStats()
+	deaths, disconnects, drops := stats.GetSessionCounters()
+	end := time.Now()
+
+	report := &SessionReport{
+		Start:           stats.StartTime,
+		End:             end,
+		UptimeSec:       int64(end.Sub(stats.StartTime).Seconds()),
+		Kills:           kills,
+		KillsPerMinute:  kpm,
+		KillsPerHour:    kph,
+		Mobs:            []SessionMobStats{},
+		Deaths:          deaths,
+		Disconnects:     disconnects,
+		StealsAbandoned: stats.GetStealsAbandoned(),
+		DropsPickedUp:   drops,
+	}
+
+	// MobKillTimes only covers this run, unlike the persisted MobKills
+	for name, killTime := range stats.GetMobKillTimes() {
+		report.Mobs = append(report.Mobs, SessionMobStats{
+			Name:  name,
+			Kills: killTime.Kills,
+			MinMs: killTime.Min.Milliseconds(),
+			MaxMs: killTime.Max.Milliseconds(),
+			AvgMs: killTime.Average().Milliseconds(),
+		})
+	}
+	sort.Slice(report.Mobs, func(i, j int) bool {
+		if report.Mobs[i].Kills != report.Mobs[j].Kills {
+			return report.Mobs[i].Kills > report.Mobs[j].Kills
+		}
+		return report.Mobs[i].Name < report.Mobs[j].Name
+	})
+
+	return report
+}
+
+// Text formats the report for reading
+func (r *SessionReport) Text() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Session report\n")
+	fmt.Fprintf(&sb, "==============\n\n")
+	fmt.Fprintf(&sb, "Start:            %s\n", r.Start.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "End:              %s\n", r.End.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "Uptime:           %s\n\n", FormatDuration(time.Duration(r.UptimeSec)*time.Second))
+	fmt.Fprintf(&sb, "Kills:            %d\n", r.Kills)
+	fmt.Fprintf(&sb, "Kills per minute: %.2f\n", r.KillsPerMinute)
+	fmt.Fprintf(&sb, "Kills per hour:   %.0f\n", r.KillsPerHour)
+	fmt.Fprintf(&sb, "Deaths:           %d\n", r.Deaths)
+	fmt.Fprintf(&sb, "Disconnects:      %d\n", r.Disconnects)
+	fmt.Fprintf(&sb, "Steals abandoned: %d\n", r.StealsAbandoned)
+	fmt.Fprintf(&sb, "Drops picked up:  %d\n", r.DropsPickedUp)
+
+	if len(r.Mobs) > 0 {
+		fmt.Fprintf(&sb, "\nKills per mob:\n")
+		for _, mob := range r.Mobs {
+			fmt.Fprintf(&sb, "  %-24s %5d  avg %6dms  min %6dms  max %6dms\n",
+				mob.Name, mob.Kills, mob.AvgMs, mob.MinMs, mob.MaxMs)
+		}
+	}
+
+	return sb.String()
+}
+
+// Save writes the report to dir as session-<start>.txt and session-<start>.json
+func (r *SessionReport) Save(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	base := filepath.Join(dir, reportPrefix+r.Start.Format("20060102-150405"))
+	if err := os.WriteFile(base+".txt", []byte(r.Text()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.WriteFile(base+".json", data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return base, nil
+}
+
+// writeSessionReport saves the report of this run to Config.ReportDir, called
+// on shutdown before the logger is closed
+func (b *Bot) writeSessionReport() {
+	b.config.mu.RLock()
+	dir := b.config.ReportDir
+	b.config.mu.RUnlock()
+
+	if dir == "" {
+		return
+	}
+
+	base, err := NewSessionReport(b.stats).Save(dir)
+	if err != nil {
+		LogWarn("Failed to save session report: %v", err)
+		return
+	}
+	LogInfo("Session report saved to %s.txt and %s.json", base, base)
+}
@@ -15,12 +15,19 @@
 //   - Configurable message list with cycling
 //   - Adjustable shout interval
 //   - Automatic empty message filtering
-//   - Chat box automation (open, type, send, close)
+//   - Chat box automation (open, type character by character, send, close)
 //   - Timing variations to appear more natural
+//
+// Stopping:
+// Mode changes are queued to the main loop (Bot.RequestMode), so Stop() runs
+// between iterations on the goroutine that sends the keys. Typing still checks
+// the stop flag between characters and never sends a half-typed message;
+// Stop() itself clears the chat input and releases held keys.
 package main
 
 import (
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -58,6 +65,11 @@ type ShoutBehavior struct {
 
 	// Message cycling
 	currentMessageIndex int
+
+	// Stop handling
+	movement *MovementCoordinator // Last movement coordinator used, for cleanup in Stop
+	stopped  atomic.Bool          // Set by Stop, aborts typing
+	chatOpen atomic.Bool          // Chat input is open with pending text
 }
 
 // NewShoutBehavior creates a new shout behavior
@@ -76,8 +88,20 @@ func (sb *ShoutBehavior) GetState() string {
 	return sb.state.String()
 }
 
+// PreferredInterval returns config.ShoutingCaptureInterval (0 = CaptureInterval)
+func (sb *ShoutBehavior) PreferredInterval(config *Config) int {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+	return config.ShoutingCaptureInterval
+}
+
 // Run executes one iteration of shout behavior
 func (sb *ShoutBehavior) Run(analyzer *ImageAnalyzer, movement *MovementCoordinator, config *Config, stats *Statistics) error {
+	if sb.stopped.Load() {
+		return nil
+	}
+	sb.movement = movement
+
 	// Update configuration
 	sb.updateConfig(config)
 
@@ -175,13 +199,20 @@ func (sb *ShoutBehavior) performShout(movement *MovementCoordinator, message str
 
 	// Open chatbox
 	movement.PressKey("Enter")
+	sb.chatOpen.Store(true)
 	movement.WaitRandom(100, 250)
 
-	// Type message
-	movement.TypeText(message)
+	// Type message character by character, stop if the mode was switched away
+	if !movement.TypeTextChars(message, sb.stopped.Load) {
+		LogDebug("Shout aborted while typing")
+		return
+	}
 	movement.WaitRandom(100, 200)
 
-	// Send message
+	// Send message (unless Stop already cleared the input)
+	if !sb.chatOpen.Swap(false) {
+		return
+	}
 	movement.PressKey("Enter")
 	movement.WaitRandom(100, 250)
 
@@ -190,7 +221,19 @@ func (sb *ShoutBehavior) performShout(movement *MovementCoordinator, message str
 	movement.Wait(100 * time.Millisecond)
 }
 
-// Stop stops the shout behavior
+// Stop stops the shout behavior, clearing any half-typed message and releasing held keys
 func (sb *ShoutBehavior) Stop() {
+	sb.stopped.Store(true)
 	sb.state = ShoutStateIdle
+
+	if sb.movement == nil {
+		return
+	}
+
+	// Discard pending chat input
+	if sb.chatOpen.Swap(false) {
+		sb.movement.action.SendText("")
+		sb.movement.PressKey("Escape")
+	}
+	sb.movement.StopAllMovement()
 }
@@ -0,0 +1,97 @@
+// Package main - snapshot.go
+//
+// This file saves the captured frame to disk every Config.SnapshotIntervalSec
+// seconds, so a session can be reviewed as a timelapse.
+//
+// The main loop hands its already captured frame to snapshotWorker through a
+// buffered channel; encoding and disk writes never block the loop. Only the
+// newest Config.SnapshotRetention files are kept.
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotPrefix starts every snapshot file name, only these files are pruned
+const snapshotPrefix = "snapshot-"
+
+// queueSnapshot sends img to snapshotWorker if Config.SnapshotIntervalSec has
+// passed since the last snapshot. Skipped if the worker is still busy.
+func (b *Bot) queueSnapshot(img *image.RGBA) {
+	b.config.mu.RLock()
+	interval := b.config.SnapshotIntervalSec
+	b.config.mu.RUnlock()
+
+	if interval <= 0 || img == nil || time.Since(b.lastSnapshot) < time.Duration(interval)*time.Second {
+		return
+	}
+
+	select {
+	case b.snapshotChan <- img:
+		b.lastSnapshot = time.Now()
+	default:
+		LogDebug("Snapshot worker is busy, skipping this frame")
+	}
+}
+
+// snapshotWorker saves the frames queued by queueSnapshot
+func (b *Bot) snapshotWorker() {
+	LogInfo("Snapshot worker started")
+	defer LogInfo("Snapshot worker stopped")
+
+	for img := range b.snapshotChan {
+		b.config.mu.RLock()
+		dir := b.config.SnapshotDir
+		retention := b.config.SnapshotRetention
+		b.config.mu.RUnlock()
+
+		name := snapshotPrefix + time.Now().Format("20060102-150405.000") + ".png"
+		path := filepath.Join(dir, name)
+		if err := savePNG(path, img); err != nil {
+			LogWarn("Failed to save snapshot %s: %v", path, err)
+			continue
+		}
+		LogDebug("Snapshot saved: %s", path)
+
+		if err := pruneSnapshots(dir, retention); err != nil {
+			LogWarn("Failed to prune snapshots in %s: %v", dir, err)
+		}
+	}
+}
+
+// pruneSnapshots removes the oldest snapshots in dir until at most keep are
+// left (keep <= 0 keeps all). Timestamped names sort oldest first.
+func pruneSnapshots(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), snapshotPrefix) {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) <= keep {
+		return nil
+	}
+
+	sort.Strings(names)
+	for _, name := range names[:len(names)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("remove %s: %w", name, err)
+		}
+	}
+	return nil
+}
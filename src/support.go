@@ -32,11 +32,13 @@
 //   - Target HP monitoring and healing
 //   - Periodic buffing (separate cooldowns for self and target)
 //   - Resurrection support
+//   - Party member healing (lowest HP first, from the party HP bars)
 //   - Self-care (own HP/MP/FP management)
 //   - Random camera movement to avoid AFK detection
 package main
 
 import (
+	"strings"
 	"time"
 )
 
@@ -107,6 +109,12 @@ type SupportBehavior struct {
 
 	// Obstacle avoidance
 	avoidObstacleDirection string
+
+	// Popup handling
+	lastChatLine string
+
+	// Party monitoring
+	lastNoPartyWarning time.Time
 }
 
 // NewSupportBehavior creates a new support behavior
@@ -125,6 +133,13 @@ func (sb *SupportBehavior) GetState() string {
 	return sb.state.String()
 }
 
+// PreferredInterval returns config.SupportCaptureInterval (0 = CaptureInterval)
+func (sb *SupportBehavior) PreferredInterval(config *Config) int {
+	config.mu.RLock()
+	defer config.mu.RUnlock()
+	return config.SupportCaptureInterval
+}
+
 // Run executes one iteration of support behavior
 func (sb *SupportBehavior) Run(analyzer *ImageAnalyzer, movement *MovementCoordinator, config *Config, stats *Statistics) error {
 	// Update player stats
@@ -143,6 +158,9 @@ func (sb *SupportBehavior) Run(analyzer *ImageAnalyzer, movement *MovementCoordi
 	// Check self restorations
 	sb.checkSelfRestorations(movement, config, clientStats)
 
+	// Answer party invites and trade requests
+	sb.handlePopups(analyzer, movement, config)
+
 	// Update target status
 	sb.hasTarget = clientStats.TargetOnScreen
 
@@ -151,6 +169,23 @@ func (sb *SupportBehavior) Run(analyzer *ImageAnalyzer, movement *MovementCoordi
 		return nil
 	}
 
+	// Idle when no party is shown instead of wandering around
+	if config.InParty {
+		members := analyzer.DetectPartyMembers()
+		if len(members) == 0 {
+			if time.Since(sb.lastNoPartyWarning) > 10*time.Second {
+				LogWarn("No party detected, idling")
+				sb.lastNoPartyWarning = time.Now()
+			}
+			return nil
+		}
+
+		// Heal the party member with the lowest HP first
+		if sb.healPartyMember(movement, config, members) {
+			return nil
+		}
+	}
+
 	// Random camera movement
 	sb.randomCameraMovement(movement)
 
@@ -237,7 +272,8 @@ func (sb *SupportBehavior) onFollowing(analyzer *ImageAnalyzer, movement *Moveme
 		return SupportStateSelfBuffing
 	}
 
-	// Continue following
+	// Continue following, keeping the leader centered
+	sb.centerTarget(analyzer, movement)
 	sb.followTarget(movement)
 
 	return SupportStateFollowing
@@ -436,6 +472,50 @@ func (sb *SupportBehavior) moveCirclePattern(analyzer *ImageAnalyzer, config *Co
 	}
 }
 
+// handlePopups accepts party invites from the configured leader and declines trades
+// from anyone else, based on new system chat lines
+func (sb *SupportBehavior) handlePopups(analyzer *ImageAnalyzer, movement *MovementCoordinator, config *Config) {
+	if config.AutoAcceptPartyFrom == "" && !config.AutoDeclineTrades {
+		return
+	}
+
+	lines := analyzer.browser.ReadChatLines(5)
+	if len(lines) == 0 {
+		return
+	}
+
+	// Only handle lines after the last one seen
+	start := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i] == sb.lastChatLine {
+			start = i + 1
+			break
+		}
+	}
+	sb.lastChatLine = lines[len(lines)-1]
+
+	for _, line := range lines[start:] {
+		if match := gameMessages.Match(line, MessagePartyInvite); len(match) > 1 {
+			if config.AutoAcceptPartyFrom != "" && strings.EqualFold(match[1], config.AutoAcceptPartyFrom) {
+				LogInfo("Accepting party invite from %s", match[1])
+				movement.AnswerPopup(analyzer.screenInfo, true)
+			} else {
+				LogInfo("Ignoring party invite from %s", match[1])
+			}
+			continue
+		}
+
+		if match := gameMessages.Match(line, MessageTradeRequest); len(match) > 1 {
+			if config.AutoDeclineTrades && !strings.EqualFold(match[1], config.AutoAcceptPartyFrom) {
+				LogInfo("Declining trade from %s", match[1])
+				movement.AnswerPopup(analyzer.screenInfo, false)
+			} else {
+				LogInfo("Leaving trade request from %s open", match[1])
+			}
+		}
+	}
+}
+
 // selectPartyLeader selects the party leader
 func (sb *SupportBehavior) selectPartyLeader(movement *MovementCoordinator) {
 	LogDebug("Selecting party leader")
@@ -455,6 +535,58 @@ func (sb *SupportBehavior) selectPartyLeader(movement *MovementCoordinator) {
 	time.Sleep(500 * time.Millisecond)
 }
 
+// healPartyMember selects and heals the party member with the lowest HP if it is
+// below the heal threshold. Returns true if a member was healed.
+func (sb *SupportBehavior) healPartyMember(movement *MovementCoordinator, config *Config, members []PartyMember) bool {
+	lowest := members[0]
+	for _, member := range members[1:] {
+		if member.HP < lowest.HP {
+			lowest = member
+		}
+	}
+
+	if lowest.HP >= config.HealThreshold {
+		return false
+	}
+
+	slots := config.HealSlots
+	if len(slots) == 0 {
+		slots = config.BuffSlots
+	}
+	if len(slots) == 0 {
+		return false
+	}
+
+	LogDebug("Healing party member %d (HP: %d%%)", lowest.Index, lowest.HP)
+
+	// Clicking a party bar selects that member as target
+	movement.ClickTargetWithin(lowest.Bounds.Center(), lowest.Bounds)
+	movement.Wait(150 * time.Millisecond)
+	movement.UseSkill(slots)
+	sb.wait(2000 * time.Millisecond)
+
+	// Reselect the leader afterwards
+	if lowest.Index != 0 {
+		sb.state = SupportStateNoTarget
+	}
+	return true
+}
+
+// centerTarget rotates the camera so the followed target stays near the screen center
+func (sb *SupportBehavior) centerTarget(analyzer *ImageAnalyzer, movement *MovementCoordinator) {
+	marker := analyzer.DetectTargetMarkerPosition()
+	if marker == nil {
+		return
+	}
+
+	offset := marker.X - analyzer.screenInfo.Center().X
+	if offset > 80 {
+		movement.RotateRight(50 * time.Millisecond)
+	} else if offset < -80 {
+		movement.RotateLeft(50 * time.Millisecond)
+	}
+}
+
 // followTarget follows the current target
 func (sb *SupportBehavior) followTarget(movement *MovementCoordinator) {
 	if sb.hasTarget {
@@ -0,0 +1,210 @@
+//go:build !nocv
+
+// Package main - templates.go
+//
+// Template-matching mob detection.
+// Matches saved nameplate images (templates/*.png) against the screen as an
+// alternative to name color detection, which can be fooled by UI text and
+// environment colors of the same hue.
+//
+// Matching runs in grayscale at several template scales, since the nameplate
+// size changes with the game zoom level.
+//
+// Only built with OpenCV; the Detector (detector_opencv.go) owns the matcher.
+package main
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// templateScales are the template sizes tried against the frame
+var templateScales = []float64{0.7, 0.8, 0.9, 1.0, 1.1, 1.2, 1.3}
+
+// maxTemplateMatches limits matches per template and scale
+const maxTemplateMatches = 10
+
+// mobTemplate is a loaded nameplate template
+type mobTemplate struct {
+	name   string     // File name without extension, used as Target.Name
+	scaled []gocv.Mat // Grayscale template per entry in templateScales
+}
+
+// templateMatch is a single match candidate
+type templateMatch struct {
+	name   string
+	bounds Bounds
+	score  float32
+}
+
+// TemplateMatcher finds mob nameplates by template matching
+type TemplateMatcher struct {
+	dir       string
+	templates []mobTemplate
+	mu        sync.Mutex
+}
+
+// NewTemplateMatcher loads all PNG templates from dir
+func NewTemplateMatcher(dir string) *TemplateMatcher {
+	tm := &TemplateMatcher{dir: dir}
+	tm.load()
+	return tm
+}
+
+// load reads and pre-scales every PNG in the template directory
+func (tm *TemplateMatcher) load() {
+	entries, err := os.ReadDir(tm.dir)
+	if err != nil {
+		LogWarn("Failed to read template directory %s: %v", tm.dir, err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".png") {
+			continue
+		}
+
+		path := filepath.Join(tm.dir, entry.Name())
+		mat := gocv.IMRead(path, gocv.IMReadGrayScale)
+		if mat.Empty() {
+			LogWarn("Failed to load template %s", path)
+			continue
+		}
+
+		tmpl := mobTemplate{name: strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))}
+		for _, scale := range templateScales {
+			scaled := gocv.NewMat()
+			gocv.Resize(mat, &scaled, image.Point{}, scale, scale, gocv.InterpolationLinear)
+			tmpl.scaled = append(tmpl.scaled, scaled)
+		}
+		mat.Close()
+
+		tm.templates = append(tm.templates, tmpl)
+		LogDebug("Loaded mob template %s", tmpl.name)
+	}
+
+	LogInfo("Loaded %d mob templates from %s", len(tm.templates), tm.dir)
+}
+
+// Count returns the number of loaded templates
+func (tm *TemplateMatcher) Count() int {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return len(tm.templates)
+}
+
+// Match finds all template matches inside region with a normalized correlation
+// of at least threshold. Overlapping matches keep only the best score.
+func (tm *TemplateMatcher) Match(img *image.RGBA, region Bounds, threshold float32) []Target {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if img == nil || len(tm.templates) == 0 {
+		return nil
+	}
+
+	frame, err := gocv.ImageToMatRGB(img)
+	if err != nil {
+		LogError("Failed to convert image to mat: %v", err)
+		return nil
+	}
+	defer frame.Close()
+
+	rect := image.Rect(region.X, region.Y, region.X+region.W, region.Y+region.H).Intersect(image.Rect(0, 0, frame.Cols(), frame.Rows()))
+	if rect.Empty() {
+		return nil
+	}
+	roi := frame.Region(rect)
+	defer roi.Close()
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(roi, &gray, gocv.ColorBGRToGray)
+
+	var candidates []templateMatch
+	for _, tmpl := range tm.templates {
+		for _, scaled := range tmpl.scaled {
+			candidates = append(candidates, matchTemplate(gray, scaled, tmpl.name, threshold, rect.Min)...)
+		}
+	}
+
+	// Non-maximum suppression across templates and scales
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	var mobs []Target
+	var kept []Bounds
+	for _, c := range candidates {
+		overlaps := false
+		for _, b := range kept {
+			if boundsOverlap(c.bounds, b) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		kept = append(kept, c.bounds)
+		LogDebug("Template %s matched at (%d,%d) size %dx%d score %.2f",
+			c.name, c.bounds.X, c.bounds.Y, c.bounds.W, c.bounds.H, c.score)
+		mobs = append(mobs, Target{
+			Type:   MobPassive,
+			Bounds: c.bounds,
+			Name:   c.name,
+		})
+	}
+
+	return mobs
+}
+
+// matchTemplate returns up to maxTemplateMatches peaks of a single template.
+// offset translates ROI coordinates back to screen coordinates.
+func matchTemplate(gray, tmpl gocv.Mat, name string, threshold float32, offset image.Point) []templateMatch {
+	if tmpl.Cols() > gray.Cols() || tmpl.Rows() > gray.Rows() {
+		return nil
+	}
+
+	result := gocv.NewMat()
+	defer result.Close()
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.MatchTemplate(gray, tmpl, &result, gocv.TmCcoeffNormed, mask)
+
+	var matches []templateMatch
+	for len(matches) < maxTemplateMatches {
+		_, maxVal, _, maxLoc := gocv.MinMaxLoc(result)
+		if maxVal < threshold {
+			break
+		}
+		matches = append(matches, templateMatch{
+			name:   name,
+			bounds: Bounds{X: offset.X + maxLoc.X, Y: offset.Y + maxLoc.Y, W: tmpl.Cols(), H: tmpl.Rows()},
+			score:  maxVal,
+		})
+
+		// Blank out the peak so the next iteration finds another match
+		suppress := image.Rect(maxLoc.X-tmpl.Cols()/2, maxLoc.Y-tmpl.Rows()/2, maxLoc.X+tmpl.Cols()/2+1, maxLoc.Y+tmpl.Rows()/2+1)
+		gocv.Rectangle(&result, suppress, color.RGBA{}, -1)
+	}
+	return matches
+}
+
+// Close releases all template mats
+func (tm *TemplateMatcher) Close() {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	for _, tmpl := range tm.templates {
+		for _, scaled := range tmpl.scaled {
+			scaled.Close()
+		}
+	}
+	tm.templates = nil
+}
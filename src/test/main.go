@@ -1,10 +1,13 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
 	"math"
+	"os"
+	"path/filepath"
 	"time"
 
 	"gocv.io/x/gocv"
@@ -40,6 +43,15 @@ type DirectionInfo struct {
 	Found        bool
 }
 
+// debugBars enables dumping the matched pixels of every bar to dumpDir (-debug)
+var debugBars bool
+
+// dumpDir is where debug bar masks are written
+const dumpDir = "dumps"
+
+// barDumpScale enlarges the few-pixel-high bar dumps so single pixels are visible
+const barDumpScale = 8
+
 // DetectTargets detects monster names (red and yellow) using gocv color detection
 func DetectTargets(img gocv.Mat) []Target {
 	start := time.Now()
@@ -226,26 +238,14 @@ func DetectStats(img gocv.Mat) (Stats, bool) {
 	}, true
 }
 
-// pixelMatches checks if a pixel matches a reference color within tolerance
-func pixelMatches(pixel, ref [3]uint8, tolerance uint8) bool {
-	for i := 0; i < 3; i++ {
-		var diff uint8
-		if pixel[i] > ref[i] {
-			diff = pixel[i] - ref[i]
-		} else {
-			diff = ref[i] - pixel[i]
-		}
-		if diff > tolerance {
-			return false
-		}
-	}
-	return true
-}
+// barFillMinConfidence is the fraction of rows that must match at the right
+// edge of a bar; thinner edges are treated as stray pixels
+const barFillMinConfidence = 0.3
 
 // detectBarPercentage detects a colored bar and returns its fill percentage
 // Following the Rust implementation algorithm:
-// 1. Scan ROI for pixels matching reference colors (with tolerance)
-// 2. Find horizontal bounds (minX, maxX) of matched pixels
+// 1. Mask ROI pixels matching reference colors (with tolerance)
+// 2. Find horizontal bounds (minX, maxX) of matched columns
 // 3. Calculate percentage: barWidth / roiWidth * 100
 func detectBarPercentage(roi gocv.Mat, barType string) float64 {
 	// Define reference colors (RGB format) based on Rust implementation
@@ -310,36 +310,49 @@ func detectBarPercentage(roi gocv.Mat, barType string) float64 {
 
 	tolerance := uint8(2) // Same as Rust implementation
 
-	// Find all pixels matching any reference color
-	minX := roi.Cols()
-	maxX := 0
-	foundPixels := false
-
-	for y := 0; y < roi.Rows(); y++ {
-		for x := 0; x < roi.Cols(); x++ {
-			pixel := [3]uint8{
-				roi.GetUCharAt(y, x*3+0), // B
-				roi.GetUCharAt(y, x*3+1), // G
-				roi.GetUCharAt(y, x*3+2), // R
-			}
-
-			// Check if pixel matches any reference color
-			for _, ref := range refColors {
-				if pixelMatches(pixel, ref, tolerance) {
-					foundPixels = true
-					if x < minX {
-						minX = x
-					}
-					if x > maxX {
-						maxX = x
-					}
-					break
-				}
+	// Mask all pixels matching any reference color
+	bound := func(v uint8, delta int) float64 {
+		return float64(min(max(int(v)+delta, 0), 255))
+	}
+	mask := gocv.Zeros(roi.Rows(), roi.Cols(), gocv.MatTypeCV8U)
+	defer mask.Close()
+	colorMask := gocv.NewMat()
+	defer colorMask.Close()
+	for _, ref := range refColors {
+		t := int(tolerance)
+		lower := gocv.NewScalar(bound(ref[0], -t), bound(ref[1], -t), bound(ref[2], -t), 0)
+		upper := gocv.NewScalar(bound(ref[0], t), bound(ref[1], t), bound(ref[2], t), 0)
+		gocv.InRangeWithScalar(roi, lower, upper, &colorMask)
+		gocv.BitwiseOr(mask, colorMask, &mask)
+	}
+
+	// Sum each column to a single row and find the outermost matching columns
+	colSums := gocv.NewMat()
+	defer colSums.Close()
+	gocv.Reduce(mask, &colSums, 0, gocv.ReduceSum, gocv.MatTypeCV32F)
+
+	minX, maxX := -1, -1
+	for x := 0; x < colSums.Cols(); x++ {
+		if colSums.GetFloatAt(0, x) > 0 {
+			if minX < 0 {
+				minX = x
 			}
+			maxX = x
 		}
 	}
 
-	if !foundPixels || minX >= maxX {
+	if debugBars {
+		dumpBarMask(roi, mask, minX, maxX, barType)
+	}
+
+	if minX < 0 || minX >= maxX {
+		return 0
+	}
+
+	// Reject reads whose right edge is only a few stray pixels
+	confidence := float64(colSums.GetFloatAt(0, maxX)) / (255 * float64(roi.Rows()))
+	if confidence < barFillMinConfidence {
+		fmt.Printf("%s: fill edge confidence %.2f too low, ignoring\n", barType, confidence)
 		return 0
 	}
 
@@ -356,6 +369,43 @@ func detectBarPercentage(roi gocv.Mat, barType string) float64 {
 	return percentage
 }
 
+// dumpBarMask writes dumps/bar_<barType>.png: the bar ROI with every matched
+// pixel painted magenta and the detected minX (green) and maxX (red) columns
+// marked. Diagnostic only, detection results are not affected.
+func dumpBarMask(roi gocv.Mat, mask gocv.Mat, minX, maxX int, barType string) {
+	overlay := roi.Clone()
+	defer overlay.Close()
+	overlay.SetTo(gocv.NewScalar(255, 0, 255, 0))
+	annotated := roi.Clone()
+	defer annotated.Close()
+	overlay.CopyToWithMask(&annotated, mask)
+
+	scaled := gocv.NewMat()
+	defer scaled.Close()
+	gocv.Resize(annotated, &scaled, image.Point{}, barDumpScale, barDumpScale, gocv.InterpolationNearestNeighbor)
+
+	height := scaled.Rows()
+	if minX >= 0 {
+		x := minX * barDumpScale
+		gocv.Line(&scaled, image.Pt(x, 0), image.Pt(x, height), color.RGBA{0, 255, 0, 255}, 1)
+	}
+	if maxX >= 0 {
+		x := (maxX+1)*barDumpScale - 1
+		gocv.Line(&scaled, image.Pt(x, 0), image.Pt(x, height), color.RGBA{255, 0, 0, 255}, 1)
+	}
+
+	if err := os.MkdirAll(dumpDir, 0755); err != nil {
+		fmt.Printf("%s: failed to create %s: %v\n", barType, dumpDir, err)
+		return
+	}
+	path := filepath.Join(dumpDir, "bar_"+barType+".png")
+	if ok := gocv.IMWrite(path, scaled); !ok {
+		fmt.Printf("%s: failed to write %s\n", barType, path)
+		return
+	}
+	fmt.Printf("%s: matched pixels saved to %s (minX %d, maxX %d)\n", barType, path, minX, maxX)
+}
+
 // DetectDirection analyzes the minimap to find the best direction for monster density
 func DetectDirection(img gocv.Mat) DirectionInfo {
 	start := time.Now()
@@ -590,6 +640,9 @@ func DrawResult(img gocv.Mat, targets []Target, stats Stats, targetStats *Target
 }
 
 func main() {
+	flag.BoolVar(&debugBars, "debug", false, "save the pixels matched by each bar to "+dumpDir+"/")
+	flag.Parse()
+
 	totalStart := time.Now()
 
 	// Load the training image
@@ -8,9 +8,17 @@
 //   2. Run: go run . --train
 //   3. Check result.png for visualization
 //   4. Check Debug.log for detailed detection info
+//
+// Live dataset capture (go run . --train --live):
+//   1. The game is opened and every frame runs through all detectors
+//   2. Press Enter in the console (or Config.TrainSaveHotkey) to save a frame
+//   3. Each save writes frame_NNNN.png and frame_NNNN.json to Config.TrainDir
+//   4. Type q and Enter to quit
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
@@ -18,10 +26,21 @@ import (
 	"image/png"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 )
 
-// TrainingMode runs offline detection on train.png
-func TrainingMode() error {
+// liveTrainingInterval is the delay between captures in live training mode
+const liveTrainingInterval = 500 * time.Millisecond
+
+// TrainingMode runs offline detection on train.png, or captures a labeled
+// dataset from the running game when live is set
+func TrainingMode(live bool) error {
+	if live {
+		return liveTrainingMode()
+	}
+
 	LogInfo("=== Training Mode Started ===")
 
 	// Check if train.png exists
@@ -104,6 +123,250 @@ func TrainingMode() error {
 	return nil
 }
 
+// trainingBar is an annotated status bar in a dataset frame
+type trainingBar struct {
+	Value    int    `json:"value"`
+	Detected bool   `json:"detected"`
+	Bounds   Bounds `json:"bounds"`
+}
+
+// trainingMob is an annotated mob nameplate in a dataset frame
+type trainingMob struct {
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	Bounds Bounds `json:"bounds"`
+	Click  Point  `json:"click"`
+}
+
+// trainingAnnotation is the JSON sidecar saved next to each dataset frame
+type trainingAnnotation struct {
+	Frame          string        `json:"frame"`
+	Time           time.Time     `json:"time"`
+	Width          int           `json:"width"`
+	Height         int           `json:"height"`
+	HP             trainingBar   `json:"hp"`
+	MP             trainingBar   `json:"mp"`
+	FP             trainingBar   `json:"fp"`
+	TargetHP       trainingBar   `json:"target_hp"`
+	TargetMP       trainingBar   `json:"target_mp"`
+	TargetOnScreen bool          `json:"target_on_screen"`
+	TargetMarker   *Point        `json:"target_marker,omitempty"`
+	Casting        bool          `json:"casting"`
+	PopupOpen      bool          `json:"popup_open"`
+	ChatFocused    bool          `json:"chat_focused"`
+	InventoryFull  bool          `json:"inventory_full"`
+	Mobs           []trainingMob `json:"mobs"`
+}
+
+// newTrainingAnnotation collects the detection results of one frame
+func newTrainingAnnotation(img *image.RGBA, stats *ClientStats, mobs []Target, marker *Point, config *Config) *trainingAnnotation {
+	stats.mu.RLock()
+	annotation := &trainingAnnotation{
+		Time:           time.Now(),
+		Width:          img.Bounds().Dx(),
+		Height:         img.Bounds().Dy(),
+		HP:             newTrainingBar(stats.HP, stats.HPBar),
+		MP:             newTrainingBar(stats.MP, stats.MPBar),
+		FP:             newTrainingBar(stats.FP, stats.FPBar),
+		TargetHP:       newTrainingBar(stats.TargetHP, stats.TargetHPBar),
+		TargetMP:       newTrainingBar(stats.TargetMP, stats.TargetMPBar),
+		TargetOnScreen: stats.TargetOnScreen,
+		TargetMarker:   marker,
+		Casting:        stats.Casting,
+		PopupOpen:      stats.PopupOpen,
+		ChatFocused:    stats.ChatFocused,
+		InventoryFull:  stats.InventoryFull,
+		Mobs:           make([]trainingMob, 0, len(mobs)),
+	}
+	stats.mu.RUnlock()
+
+	for _, mob := range mobs {
+		click, _ := mob.ClickCoords(config)
+		annotation.Mobs = append(annotation.Mobs, trainingMob{
+			Type:   mobTypeLabel(mob.Type),
+			Name:   mob.Name,
+			Bounds: mob.Bounds,
+			Click:  click,
+		})
+	}
+	return annotation
+}
+
+// newTrainingBar combines a stat value with its detected bar position
+func newTrainingBar(stat *StatInfo, bar DetectedBar) trainingBar {
+	return trainingBar{Value: stat.Value, Detected: bar.Detected, Bounds: bar.Bounds}
+}
+
+// mobTypeLabel returns the display name of a mob type
+func mobTypeLabel(mobType MobType) string {
+	switch mobType {
+	case MobPassive:
+		return "Passive"
+	case MobAggressive:
+		return "Aggressive"
+	case MobViolet:
+		return "Violet"
+	}
+	return "Unknown"
+}
+
+// TrainingDataset writes frames and their annotations with auto-incrementing names
+type TrainingDataset struct {
+	Dir  string
+	next int
+}
+
+// NewTrainingDataset creates the dataset directory and continues numbering
+// after the highest frame already in it
+func NewTrainingDataset(dir string) (*TrainingDataset, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create dataset directory: %w", err)
+	}
+
+	dataset := &TrainingDataset{Dir: dir, next: 1}
+	matches, _ := filepath.Glob(filepath.Join(dir, "frame_*.png"))
+	for _, match := range matches {
+		var n int
+		if _, err := fmt.Sscanf(filepath.Base(match), "frame_%d.png", &n); err == nil && n >= dataset.next {
+			dataset.next = n + 1
+		}
+	}
+	return dataset, nil
+}
+
+// Save writes the frame as frame_NNNN.png with its annotation as frame_NNNN.json
+// and returns the frame path
+func (d *TrainingDataset) Save(img *image.RGBA, annotation *trainingAnnotation) (string, error) {
+	name := fmt.Sprintf("frame_%04d", d.next)
+	framePath := filepath.Join(d.Dir, name+".png")
+	annotation.Frame = name + ".png"
+
+	if err := savePNG(framePath, img); err != nil {
+		return "", fmt.Errorf("failed to save frame: %w", err)
+	}
+
+	data, err := json.MarshalIndent(annotation, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal annotation: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(d.Dir, name+".json"), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write annotation: %w", err)
+	}
+
+	d.next++
+	return framePath, nil
+}
+
+// liveTrainingMode opens the game, runs all detectors on every frame and saves
+// the current frame with its annotation whenever a save is requested
+func liveTrainingMode() error {
+	LogInfo("=== Live Training Mode Started ===")
+
+	data, err := LoadData()
+	if err != nil {
+		LogError("Failed to load data: %v, using defaults", err)
+		data = NewPersistentData()
+	}
+	config := data.Config
+
+	dataset, err := NewTrainingDataset(config.TrainDir)
+	if err != nil {
+		return err
+	}
+
+	browser := NewBrowser(config)
+	if err := browser.Start(data.Cookies); err != nil {
+		return fmt.Errorf("failed to start browser: %w", err)
+	}
+	defer browser.Close()
+
+	analyzer := NewImageAnalyzer(browser)
+	analyzer.GetStats().SetBarSelectRules(config.BarSelectRules)
+	analyzer.GetStats().SetBarColors(config.BarColors)
+	analyzer.SetStatusRecalibrateInterval(config.StatusRecalibrateInterval)
+	analyzer.SetPickupPetIcon(config.PickupPetIcon)
+	analyzer.SetPartyInviteTemplate(config.PartyInviteTemplate)
+	analyzer.SetDebuffIcons(config.DebuffIcons)
+
+	saveRequests := make(chan struct{}, 1)
+	requestSave := func() {
+		select {
+		case saveRequests <- struct{}{}:
+		default:
+		}
+	}
+
+	if config.TrainSaveHotkey != "" {
+		if hotkey, err := ParseHotkey(config.TrainSaveHotkey); err != nil {
+			LogWarn("Invalid training save hotkey: %v", err)
+		} else if err := listenHotkey(hotkey, requestSave); err != nil {
+			LogWarn("Failed to register training save hotkey %s: %v", hotkey, err)
+		} else {
+			LogInfo("Training save hotkey registered: %s", hotkey)
+		}
+	}
+
+	quit := make(chan struct{})
+	var quitOnce sync.Once
+	SafeGo(func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if strings.EqualFold(strings.TrimSpace(scanner.Text()), "q") {
+				break
+			}
+			requestSave()
+		}
+		quitOnce.Do(func() { close(quit) })
+	})
+
+	LogInfo("Saving frames to %s, press Enter to save the current frame, q and Enter to quit", dataset.Dir)
+
+	var lastImage *image.RGBA
+	var lastAnnotation *trainingAnnotation
+	ticker := time.NewTicker(liveTrainingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-quit:
+			LogInfo("=== Live Training Mode Completed ===")
+			return nil
+
+		case <-saveRequests:
+			if lastImage == nil {
+				LogWarn("No frame captured yet, nothing to save")
+				continue
+			}
+			path, err := dataset.Save(lastImage, lastAnnotation)
+			if err != nil {
+				LogError("Failed to save training frame: %v", err)
+				continue
+			}
+			LogInfo("Saved %s (%d mobs, HP %d%%, target HP %d%%)", path, len(lastAnnotation.Mobs), lastAnnotation.HP.Value, lastAnnotation.TargetHP.Value)
+
+		case <-ticker.C:
+			if !browser.CheckCanvasExists() {
+				continue
+			}
+			if err := analyzer.Capture(); err != nil {
+				LogDebug("Training capture failed: %v", err)
+				continue
+			}
+			img := analyzer.GetImage()
+			if img == nil {
+				continue
+			}
+
+			analyzer.UpdateStats()
+			mobs := analyzer.IdentifyMobs(config)
+			marker := analyzer.DetectTargetMarkerPosition()
+
+			lastImage = img
+			lastAnnotation = newTrainingAnnotation(img, analyzer.GetStats(), mobs, marker, config)
+		}
+	}
+}
+
 // loadPNG loads a PNG image from file
 func loadPNG(filename string) (*image.RGBA, error) {
 	file, err := os.Open(filename)
@@ -154,18 +417,15 @@ func drawDetectionResults(img *image.RGBA, mobs []Target, stats *ClientStats, co
 	// Draw mob bounding boxes
 	for i, mob := range mobs {
 		var boxColor color.RGBA
-		var label string
+		label := mobTypeLabel(mob.Type)
 
 		switch mob.Type {
 		case MobPassive:
 			boxColor = color.RGBA{R: 234, G: 234, B: 149, A: 255} // Yellow
-			label = "Passive"
 		case MobAggressive:
 			boxColor = color.RGBA{R: 179, G: 23, B: 23, A: 255} // Red
-			label = "Aggressive"
 		case MobViolet:
 			boxColor = color.RGBA{R: 182, G: 144, B: 146, A: 255} // Purple
-			label = "Violet"
 		}
 
 		// Draw bounding box
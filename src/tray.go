@@ -10,20 +10,35 @@
 //   │  ├─ Stop (idle, recognition continues)
 //   │  ├─ Farming (autonomous mob hunting)
 //   │  ├─ Support (party healing/buffing)
-//   │  └─ Shouting (auto-chat, not implemented)
+//   │  └─ Shouting (auto-chat, cycles ShoutMessages)
+//   ├─ Slot Page (F1-F9 radio, the skill bar page the slot menus below edit)
 //   ├─ Slots (3-level: Slots → Slot Type → 0-9)
 //   │  ├─ Attack Slots (checkboxes for slots 0-9)
 //   │  ├─ Heal Slots
+//   │  ├─ HP Restore Slots (food/pills)
 //   │  ├─ Buff Slots
 //   │  ├─ MP Restore Slots
 //   │  ├─ FP Restore Slots
 //   │  ├─ Pickup Slots
 //   │  └─ Pet Slot (radio buttons for slots 0-9)
 //   ├─ Slot Cooldowns (per-slot cooldown configuration)
+//   ├─ Slot Thresholds (per-slot restore threshold, Global = HP/MP/FP Threshold)
 //   ├─ Thresholds (3-level: Thresholds → Type → 0%-100%)
 //   │  ├─ HP Threshold (radio buttons 0-100 in 10% increments)
 //   │  ├─ MP Threshold
 //   │  └─ FP Threshold
+//   ├─ Mob Filter (OCR name filtering)
+//   │  ├─ Read Mob Names (toggle OCR without filters)
+//   │  ├─ <recently seen name> → Whitelist / Blacklist (up to 10 names)
+//   │  └─ Clear Filters
+//   ├─ Mob Colors (HSV name ranges)
+//   │  └─ Passive / Aggressive / Violet → H/S/V Min/Max → +5 / -5
+//   ├─ Mob Click Offset
+//   │  ├─ X / Y → +5 / -5
+//   │  └─ Scale With Nameplate Height (offsets in % of the nameplate height)
+//   ├─ Bar Colors
+//   │  ├─ Calibrate (HP/MP/FP Full) (samples the bar colors from the current frame)
+//   │  └─ Reset to Defaults
 //   ├─ Capture Frequency
 //   │  ├─ Continuous (0ms)
 //   │  ├─ 1 Second (default)
@@ -34,8 +49,8 @@
 //   └─ Quit (graceful shutdown)
 //
 // Concurrency Model:
-// The tray spawns 60+ goroutines for event handling:
-//   - 60 slot click handlers (6 slot types × 10 slots each)
+// The tray spawns 70+ goroutines for event handling:
+//   - 70 slot click handlers (7 slot types × 10 slots each)
 //   - 33 threshold handlers (3 types × 11 percentages each)
 //   - 5 capture frequency handlers
 //   - 1 mode selection handler
@@ -55,6 +70,8 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/getlantern/systray"
 )
@@ -85,6 +102,7 @@ type TrayApp struct {
 	// Slot config items - parent menus
 	attackSlotsItem    *systray.MenuItem
 	healSlotsItem      *systray.MenuItem
+	hpRestoreSlotsItem *systray.MenuItem
 	buffSlotsItem      *systray.MenuItem
 	mpRestoreSlotsItem *systray.MenuItem
 	fpRestoreSlotsItem *systray.MenuItem
@@ -93,6 +111,7 @@ type TrayApp struct {
 	// Slot submenu items (for each slot type, we have 10 slot options: 0-9)
 	attackSlotItems    [10]*systray.MenuItem
 	healSlotItems      [10]*systray.MenuItem
+	hpRestoreSlotItems [10]*systray.MenuItem
 	buffSlotItems      [10]*systray.MenuItem
 	mpRestoreSlotItems [10]*systray.MenuItem
 	fpRestoreSlotItems [10]*systray.MenuItem
@@ -102,6 +121,7 @@ type TrayApp struct {
 	// Disable buttons for each slot type
 	attackDisableItem    *systray.MenuItem
 	healDisableItem      *systray.MenuItem
+	hpRestoreDisableItem *systray.MenuItem
 	buffDisableItem      *systray.MenuItem
 	mpRestoreDisableItem *systray.MenuItem
 	fpRestoreDisableItem *systray.MenuItem
@@ -125,8 +145,48 @@ type TrayApp struct {
 	slotCooldownItem     *systray.MenuItem
 	slotCooldownSlots    [10]*systray.MenuItem // Slot 0-9 selection
 	slotCooldownTimes    [21]*systray.MenuItem // Current cooldown time options for selected slot
+
+	// Skill bar page edited by the slot menus (slot numbers, see SlotOnPage)
+	slotPageItems        [SkillPages]*systray.MenuItem // F1-F9 (radio)
+	slotPage             int                           // Page 1-9 the slot menus show
+	slotPageRefresh      []func()                      // Checkmark updates of the per-slot menus, run on page change
+	slotPageMu           sync.Mutex
+
+	// Slot threshold configuration
+	slotThresholdItem    *systray.MenuItem
+	slotThresholdSlots   [10]*systray.MenuItem // Slot 0-9 selection
+
+	// Slot test (fire a slot once)
+	testSlotItem         *systray.MenuItem
+	testSlotSlots        [10]*systray.MenuItem // Slot 0-9
+
+	// Mob filter configuration
+	mobNameOCRItem       *systray.MenuItem
+	mobFilterClearItem   *systray.MenuItem
+	mobNameItems         [10]*systray.MenuItem // Recently seen names (hidden until populated)
+	mobWhitelistItems    [10]*systray.MenuItem
+	mobBlacklistItems    [10]*systray.MenuItem
+	mobNames             [10]string // Name currently shown in each slot
+	mobNamesMu           sync.Mutex
+
+	// Mob color configuration (3 classes x 6 bounds)
+	mobColorBoundItems   [3][6]*systray.MenuItem
+
+	// Mob click offset configuration
+	mobClickOffsetItems  [2]*systray.MenuItem // X and Y offset titles
+	mobClickHeightItem   *systray.MenuItem    // Offsets in percent of the nameplate height
+
+	// Status bar color calibration
+	barCalibrateItem     *systray.MenuItem
+	barColorResetItem    *systray.MenuItem
 }
 
+// mobColorClasses and mobColorBounds name the tray "Mob Colors" entries
+var (
+	mobColorClasses = [3]string{"Passive", "Aggressive", "Violet"}
+	mobColorBounds  = [6]string{"H Min", "H Max", "S Min", "S Max", "V Min", "V Max"}
+)
+
 // NewTrayApp creates a new tray application
 func NewTrayApp(bot *Bot) *TrayApp {
 	return &TrayApp{
@@ -144,6 +204,7 @@ func (t *TrayApp) Run() {
 			t.bot.StopBehavior()
 			LogInfo("Closing browser from tray exit")
 			t.bot.browser.Close()
+			t.bot.StopAPI()
 		}
 		LogInfo("System tray exit complete")
 	})
@@ -174,10 +235,18 @@ func (t *TrayApp) onReady() {
 
 	systray.AddSeparator()
 
+	// Skill bar page edited by the slot menus below
+	t.slotPage = 1
+	slotPageMenu := systray.AddMenuItem("Slot Page", "Skill bar page (F1-F9) edited by the slot menus")
+	for i := range t.slotPageItems {
+		t.slotPageItems[i] = slotPageMenu.AddSubMenuItemCheckbox(fmt.Sprintf("F%d", i+1), "", i == 0)
+	}
+
 	// Slot configuration - with 3-level menu (Slots -> Slot Type -> 0-9)
 	slotsMenu := systray.AddMenuItem("Slots", "Configure skill slots")
 	t.attackSlotsItem = slotsMenu.AddSubMenuItem("Attack Slots", "Configure attack skill slots")
 	t.healSlotsItem = slotsMenu.AddSubMenuItem("Heal Slots", "Configure heal skill slots")
+	t.hpRestoreSlotsItem = slotsMenu.AddSubMenuItem("HP Restore Slots", "Configure HP food/pill slots")
 	t.buffSlotsItem = slotsMenu.AddSubMenuItem("Buff Slots", "Configure buff skill slots")
 	t.mpRestoreSlotsItem = slotsMenu.AddSubMenuItem("MP Restore Slots", "Configure MP restore slots")
 	t.fpRestoreSlotsItem = slotsMenu.AddSubMenuItem("FP Restore Slots", "Configure FP restore slots")
@@ -190,6 +259,7 @@ func (t *TrayApp) onReady() {
 	for i := 0; i < 10; i++ {
 		t.attackSlotItems[i] = t.attackSlotsItem.AddSubMenuItemCheckbox(fmt.Sprintf("Slot %d", i), "", false)
 		t.healSlotItems[i] = t.healSlotsItem.AddSubMenuItemCheckbox(fmt.Sprintf("Slot %d", i), "", false)
+		t.hpRestoreSlotItems[i] = t.hpRestoreSlotsItem.AddSubMenuItemCheckbox(fmt.Sprintf("Slot %d", i), "", false)
 		t.buffSlotItems[i] = t.buffSlotsItem.AddSubMenuItemCheckbox(fmt.Sprintf("Slot %d", i), "", false)
 		t.mpRestoreSlotItems[i] = t.mpRestoreSlotsItem.AddSubMenuItemCheckbox(fmt.Sprintf("Slot %d", i), "", false)
 		t.fpRestoreSlotItems[i] = t.fpRestoreSlotsItem.AddSubMenuItemCheckbox(fmt.Sprintf("Slot %d", i), "", false)
@@ -200,6 +270,7 @@ func (t *TrayApp) onReady() {
 	// Add "Disable All" option for each slot type
 	t.attackDisableItem = t.attackSlotsItem.AddSubMenuItem("Disable All", "Clear all attack slot selections")
 	t.healDisableItem = t.healSlotsItem.AddSubMenuItem("Disable All", "Clear all heal slot selections")
+	t.hpRestoreDisableItem = t.hpRestoreSlotsItem.AddSubMenuItem("Disable All", "Clear all HP restore slot selections")
 	t.buffDisableItem = t.buffSlotsItem.AddSubMenuItem("Disable All", "Clear all buff slot selections")
 	t.mpRestoreDisableItem = t.mpRestoreSlotsItem.AddSubMenuItem("Disable All", "Clear all MP restore slot selections")
 	t.fpRestoreDisableItem = t.fpRestoreSlotsItem.AddSubMenuItem("Disable All", "Clear all FP restore slot selections")
@@ -220,6 +291,18 @@ func (t *TrayApp) onReady() {
 	// Note: Cooldown time submenus will be created dynamically when a slot is selected
 	// to avoid creating 10*21=210 menu items upfront
 
+	// Slot threshold configuration - with 3-level menu (Slot Thresholds -> Slot 0-9 -> Global/10%-100%)
+	t.slotThresholdItem = systray.AddMenuItem("Slot Thresholds", "Configure the HP/MP/FP threshold of individual restore slots")
+	for i := 0; i < 10; i++ {
+		t.slotThresholdSlots[i] = t.slotThresholdItem.AddSubMenuItem(fmt.Sprintf("Slot %d", i), fmt.Sprintf("Configure restore threshold for slot %d", i))
+	}
+
+	// Slot test - fires the clicked slot once to check the key mapping
+	t.testSlotItem = systray.AddMenuItem("Test Slot", "Use a slot once to check which skill it triggers")
+	for i := 0; i < 10; i++ {
+		t.testSlotSlots[i] = t.testSlotItem.AddSubMenuItem(fmt.Sprintf("Slot %d", i), fmt.Sprintf("Use slot %d once (switches to its page)", i))
+	}
+
 	// Threshold configuration - with 3-level menu (Thresholds -> Threshold Type -> 0-100%)
 	thresholdMenu := systray.AddMenuItem("Thresholds", "Configure thresholds")
 	t.hpThresholdItem = thresholdMenu.AddSubMenuItem("HP Threshold", "Set HP heal threshold")
@@ -239,6 +322,55 @@ func (t *TrayApp) onReady() {
 
 	systray.AddSeparator()
 
+	// Mob filter configuration - names are filled in from recently recognized mobs
+	mobFilterMenu := systray.AddMenuItem("Mob Filter", "Filter mobs by recognized name")
+	t.mobNameOCRItem = mobFilterMenu.AddSubMenuItemCheckbox("Read Mob Names", "Recognize mob names even without filters", false)
+	for i := 0; i < 10; i++ {
+		t.mobNameItems[i] = mobFilterMenu.AddSubMenuItem("", "")
+		t.mobWhitelistItems[i] = t.mobNameItems[i].AddSubMenuItemCheckbox("Whitelist", "Only attack whitelisted mobs", false)
+		t.mobBlacklistItems[i] = t.mobNameItems[i].AddSubMenuItemCheckbox("Blacklist", "Never attack blacklisted mobs", false)
+		t.mobNameItems[i].Hide()
+	}
+	t.mobFilterClearItem = mobFilterMenu.AddSubMenuItem("Clear Filters", "Clear whitelist and blacklist")
+	t.updateMobFilterItems()
+
+	systray.AddSeparator()
+
+	// Mob color configuration - with 4-level menu (Mob Colors -> Class -> Bound -> +5/-5)
+	mobColorsMenu := systray.AddMenuItem("Mob Colors", "Adjust mob name HSV color ranges")
+	for c, class := range mobColorClasses {
+		classItem := mobColorsMenu.AddSubMenuItem(class, fmt.Sprintf("Adjust %s name color range", class))
+		for b := range mobColorBounds {
+			boundItem := classItem.AddSubMenuItem("", "")
+			t.mobColorBoundItems[c][b] = boundItem
+			go t.handleMobColorClick(c, b, 5, boundItem.AddSubMenuItem("+5", "Increase by 5"))
+			go t.handleMobColorClick(c, b, -5, boundItem.AddSubMenuItem("-5", "Decrease by 5"))
+		}
+	}
+	t.updateMobColorItems()
+
+	// Mob click offset configuration (Mob Click Offset -> X/Y -> +5/-5, mode toggle)
+	mobClickMenu := systray.AddMenuItem("Mob Click Offset", "Adjust where mobs are clicked relative to their nameplate")
+	for axis, name := range []string{"X", "Y"} {
+		axisItem := mobClickMenu.AddSubMenuItem("", "")
+		t.mobClickOffsetItems[axis] = axisItem
+		go t.handleMobClickOffsetClick(axis, 5, axisItem.AddSubMenuItem("+5", fmt.Sprintf("Increase %s offset by 5", name)))
+		go t.handleMobClickOffsetClick(axis, -5, axisItem.AddSubMenuItem("-5", fmt.Sprintf("Decrease %s offset by 5", name)))
+	}
+	t.mobClickHeightItem = mobClickMenu.AddSubMenuItemCheckbox("Scale With Nameplate Height", "Offsets are percent of the nameplate height instead of pixels", false)
+	go t.handleMobClickModeClick()
+	t.updateMobClickItems()
+
+	// Status bar color calibration (Bar Colors -> Calibrate / Reset)
+	barColorsMenu := systray.AddMenuItem("Bar Colors", "Calibrate the HP/MP/FP bar colors")
+	t.barCalibrateItem = barColorsMenu.AddSubMenuItem("Calibrate (HP/MP/FP Full)", "Fill HP/MP/FP first, then sample the bar colors from the current frame")
+	t.barColorResetItem = barColorsMenu.AddSubMenuItem("Reset to Defaults", "Use the built-in bar colors")
+	go t.handleBarCalibrateClick()
+	go t.handleBarColorResetClick()
+	t.updateBarColorItems()
+
+	systray.AddSeparator()
+
 	// Capture frequency configuration
 	t.captureFreqItem = systray.AddMenuItem("Capture Frequency", "Configure capture frequency")
 	t.captureFreqItems[0] = t.captureFreqItem.AddSubMenuItemCheckbox("Continuous (0ms)", "", false)
@@ -277,6 +409,7 @@ func (t *TrayApp) handleEvents(quitItem *systray.MenuItem) {
 	for i := 0; i < 10; i++ {
 		go t.handleSlotClick("attack", i, t.attackSlotItems[i])
 		go t.handleSlotClick("heal", i, t.healSlotItems[i])
+		go t.handleSlotClick("hp", i, t.hpRestoreSlotItems[i])
 		go t.handleSlotClick("buff", i, t.buffSlotItems[i])
 		go t.handleSlotClick("mp", i, t.mpRestoreSlotItems[i])
 		go t.handleSlotClick("fp", i, t.fpRestoreSlotItems[i])
@@ -287,6 +420,7 @@ func (t *TrayApp) handleEvents(quitItem *systray.MenuItem) {
 	// Start goroutines for handling disable clicks
 	go t.handleDisableClick("attack", t.attackDisableItem)
 	go t.handleDisableClick("heal", t.healDisableItem)
+	go t.handleDisableClick("hp", t.hpRestoreDisableItem)
 	go t.handleDisableClick("buff", t.buffDisableItem)
 	go t.handleDisableClick("mp", t.mpRestoreDisableItem)
 	go t.handleDisableClick("fp", t.fpRestoreDisableItem)
@@ -297,6 +431,21 @@ func (t *TrayApp) handleEvents(quitItem *systray.MenuItem) {
 		go t.handleSlotCooldownClick(i, t.slotCooldownSlots[i])
 	}
 
+	// Start goroutines for handling slot page clicks
+	for i := range t.slotPageItems {
+		go t.handleSlotPageClick(i+1, t.slotPageItems[i])
+	}
+
+	// Start goroutines for handling slot threshold clicks
+	for i := 0; i < 10; i++ {
+		go t.handleSlotThresholdClick(i, t.slotThresholdSlots[i])
+	}
+
+	// Start goroutines for handling slot test clicks
+	for i := 0; i < 10; i++ {
+		go t.handleTestSlotClick(i, t.testSlotSlots[i])
+	}
+
 	// Start goroutines for handling threshold clicks
 	for i := 0; i <= 10; i++ {
 		go t.handleThresholdClick("hp", i*10, t.hpThresholdItems[i])
@@ -304,6 +453,14 @@ func (t *TrayApp) handleEvents(quitItem *systray.MenuItem) {
 		go t.handleThresholdClick("fp", i*10, t.fpThresholdItems[i])
 	}
 
+	// Start goroutines for handling mob filter clicks
+	for i := 0; i < 10; i++ {
+		go t.handleMobFilterClick(i, true, t.mobWhitelistItems[i])
+		go t.handleMobFilterClick(i, false, t.mobBlacklistItems[i])
+	}
+	go t.handleMobNameOCRClick()
+	go t.handleMobFilterClearClick()
+
 	// Start goroutines for handling capture frequency clicks
 	intervals := []int{0, 1000, 2000, 3000, 4000}
 	for i := 0; i < 5; i++ {
@@ -313,21 +470,23 @@ func (t *TrayApp) handleEvents(quitItem *systray.MenuItem) {
 	for {
 		select {
 		case <-t.stopItem.ClickedCh:
-			t.onModeClicked("Stop")
+			t.bot.RequestMode("Stop")
 		case <-t.farmingItem.ClickedCh:
-			t.onModeClicked("Farming")
+			t.bot.RequestMode("Farming")
 		case <-t.supportItem.ClickedCh:
-			t.onModeClicked("Support")
+			t.bot.RequestMode("Support")
 		case <-t.shoutingItem.ClickedCh:
-			t.onModeClicked("Shouting")
+			t.bot.RequestMode("Shouting")
 		case <-quitItem.ClickedCh:
 			LogInfo("Quit requested by user")
 			LogInfo("Stopping bot...")
 			t.bot.StopBehavior()
 			LogInfo("Saving state...")
 			t.bot.SaveState()
+			t.bot.writeSessionReport()
 			LogInfo("Closing browser...")
 			t.bot.browser.Close()
+			t.bot.StopAPI()
 			LogInfo("Closing logger...")
 			CloseLogger()
 			LogInfo("Quitting system tray...")
@@ -338,7 +497,8 @@ func (t *TrayApp) handleEvents(quitItem *systray.MenuItem) {
 	}
 }
 
-// onModeClicked handles mode selection
+// onModeClicked applies a mode selection, called by the main loop for queued
+// mode requests (see Bot.RequestMode)
 func (t *TrayApp) onModeClicked(mode string) {
 	LogInfo("Mode changed to: %s", mode)
 
@@ -359,7 +519,6 @@ func (t *TrayApp) onModeClicked(mode string) {
 		t.shoutingItem.Check()
 	}
 
-	// Change bot mode (this will automatically switch behavior in main loop)
 	t.bot.ChangeMode(mode)
 }
 
@@ -370,21 +529,29 @@ func (t *TrayApp) updateStatus(status string) {
 
 // UpdateStatus updates the status from external source
 func (t *TrayApp) UpdateStatus(mode string) {
-	if mode == "Stop" {
+	t.updateMobFilterItems()
+
+	if mode == "Stop" && t.bot.SessionComplete() != "" {
+		t.updateStatus(fmt.Sprintf("Mode: %s (Session complete: %s)", mode, t.bot.SessionComplete()))
+	} else if mode == "Stop" {
 		t.updateStatus(fmt.Sprintf("Mode: %s (Idle)", mode))
+	} else if fb, ok := t.bot.behavior.(*FarmingBehavior); ok && fb.HaltReason() != "" {
+		t.updateStatus(fmt.Sprintf("Mode: %s (Halted: %s)", mode, fb.HaltReason()))
 	} else {
-		kills, kpm, _, uptime := t.bot.stats.GetStats()
+		kills, kpm, _, uptime, _ := t.bot.stats.GetStats()
 		status := fmt.Sprintf("Mode: %s | %d kills | %.1f/min | %s", mode, kills, kpm, uptime)
 		t.updateStatus(status)
 	}
 }
 
-// handleSlotClick handles slot selection clicks
-func (t *TrayApp) handleSlotClick(slotType string, slotNum int, menuItem *systray.MenuItem) {
+// handleSlotClick handles slot selection clicks of slot index (0-9) on the
+// selected skill bar page
+func (t *TrayApp) handleSlotClick(slotType string, index int, menuItem *systray.MenuItem) {
 	for {
 		<-menuItem.ClickedCh
 
 		// Toggle the slot
+		slotNum := t.slotNum(index)
 		config := t.bot.config
 		config.mu.Lock()
 
@@ -394,6 +561,8 @@ func (t *TrayApp) handleSlotClick(slotType string, slotNum int, menuItem *systra
 			slots = &config.AttackSlots
 		case "heal":
 			slots = &config.HealSlots
+		case "hp":
+			slots = &config.HPRestoreSlots
 		case "buff":
 			slots = &config.BuffSlots
 		case "mp":
@@ -467,12 +636,14 @@ func (t *TrayApp) updateSlotCheckmarks() {
 	config.mu.RLock()
 	defer config.mu.RUnlock()
 
+	page := t.selectedPage()
+
 	// Helper function to update checkmarks for a slot type
 	updateSlots := func(items [10]*systray.MenuItem, configSlots []int) {
 		for i := 0; i < 10; i++ {
 			checked := false
 			for _, slot := range configSlots {
-				if slot == i {
+				if slot == SlotOnPage(page, i) {
 					checked = true
 					break
 				}
@@ -487,6 +658,7 @@ func (t *TrayApp) updateSlotCheckmarks() {
 
 	updateSlots(t.attackSlotItems, config.AttackSlots)
 	updateSlots(t.healSlotItems, config.HealSlots)
+	updateSlots(t.hpRestoreSlotItems, config.HPRestoreSlots)
 	updateSlots(t.buffSlotItems, config.BuffSlots)
 	updateSlots(t.mpRestoreSlotItems, config.MPRestoreSlots)
 	updateSlots(t.fpRestoreSlotItems, config.FPRestoreSlots)
@@ -522,8 +694,9 @@ func (t *TrayApp) updatePickupPetCheckmarks() {
 	defer config.mu.RUnlock()
 
 	// Update pet slot (radio button behavior)
+	page := t.selectedPage()
 	for i := 0; i < 10; i++ {
-		if config.PickupPetSlot == i {
+		if config.PickupPetSlot == SlotOnPage(page, i) {
 			t.pickupPetSlotItems[i].Check()
 		} else {
 			t.pickupPetSlotItems[i].Uncheck()
@@ -573,10 +746,11 @@ func (t *TrayApp) updateCaptureFreqCheckmarks() {
 }
 
 // handlePickupPetSlotClick handles pet slot selection clicks (radio button)
-func (t *TrayApp) handlePickupPetSlotClick(slotNum int, menuItem *systray.MenuItem) {
+func (t *TrayApp) handlePickupPetSlotClick(index int, menuItem *systray.MenuItem) {
 	for {
 		<-menuItem.ClickedCh
 
+		slotNum := t.slotNum(index)
 		config := t.bot.config
 		config.mu.Lock()
 		// Toggle: if already selected, disable it (-1)
@@ -615,6 +789,8 @@ func (t *TrayApp) handleDisableClick(slotType string, menuItem *systray.MenuItem
 			config.AttackSlots = []int{}
 		case "heal":
 			config.HealSlots = []int{}
+		case "hp":
+			config.HPRestoreSlots = []int{}
 		case "buff":
 			config.BuffSlots = []int{}
 		case "mp":
@@ -637,9 +813,136 @@ func (t *TrayApp) handleDisableClick(slotType string, menuItem *systray.MenuItem
 	}
 }
 
+// handleTestSlotClick queues a one-off use of the slot, run by the main loop
+// between iterations so it does not interleave with the behavior's inputs
+func (t *TrayApp) handleTestSlotClick(index int, menuItem *systray.MenuItem) {
+	for {
+		<-menuItem.ClickedCh
+
+		slotNum := t.slotNum(index)
+		select {
+		case t.bot.slotTests <- slotNum:
+			LogInfo("Slot test requested: slot %d", slotNum)
+		default:
+			LogDebug("Slot test already pending, ignoring slot %d", slotNum)
+		}
+	}
+}
+
+// handleSlotPageClick selects the skill bar page (1-9) edited by the slot menus
+func (t *TrayApp) handleSlotPageClick(page int, menuItem *systray.MenuItem) {
+	for {
+		<-menuItem.ClickedCh
+
+		t.slotPageMu.Lock()
+		t.slotPage = page
+		refresh := append([]func(){}, t.slotPageRefresh...)
+		t.slotPageMu.Unlock()
+
+		for i, item := range t.slotPageItems {
+			if i+1 == page {
+				item.Check()
+			} else {
+				item.Uncheck()
+			}
+		}
+		t.updateSlotCheckmarks()
+		t.updatePickupPetCheckmarks()
+		for _, update := range refresh {
+			update()
+		}
+
+		LogInfo("Slot menus show skill bar page F%d", page)
+	}
+}
+
+// selectedPage returns the skill bar page (1-9) the slot menus edit
+func (t *TrayApp) selectedPage() int {
+	t.slotPageMu.Lock()
+	defer t.slotPageMu.Unlock()
+	if t.slotPage == 0 {
+		return 1
+	}
+	return t.slotPage
+}
+
+// slotNum returns the slot number of slot index (0-9) on the selected page
+func (t *TrayApp) slotNum(index int) int {
+	return SlotOnPage(t.selectedPage(), index)
+}
+
+// onSlotPageChange registers a checkmark update of a per-slot menu, run when
+// another skill bar page is selected
+func (t *TrayApp) onSlotPageChange(update func()) {
+	t.slotPageMu.Lock()
+	t.slotPageRefresh = append(t.slotPageRefresh, update)
+	t.slotPageMu.Unlock()
+}
+
+// handleSlotThresholdClick handles slot threshold configuration menu clicks
+// This shows a submenu with Global (use the HP/MP/FP threshold) and 10%-100%
+func (t *TrayApp) handleSlotThresholdClick(index int, menuItem *systray.MenuItem) {
+	// Index 0 is Global, index i is i*10%
+	var thresholdItems [11]*systray.MenuItem
+	thresholdItems[0] = menuItem.AddSubMenuItemCheckbox("Global", "Use the HP/MP/FP threshold", false)
+	for i := 1; i <= 10; i++ {
+		thresholdItems[i] = menuItem.AddSubMenuItemCheckbox(fmt.Sprintf("%d%%", i*10), "", false)
+	}
+
+	updateChecks := func() {
+		config := t.bot.config
+		config.mu.RLock()
+		threshold, ok := config.SlotThresholds[t.slotNum(index)]
+		config.mu.RUnlock()
+		for i, item := range thresholdItems {
+			if (i == 0 && !ok) || (i > 0 && ok && i*10 == threshold) {
+				item.Check()
+			} else {
+				item.Uncheck()
+			}
+		}
+	}
+
+	for i := range thresholdItems {
+		go func(percent int, item *systray.MenuItem) {
+			for {
+				<-item.ClickedCh
+
+				slotNum := t.slotNum(index)
+				config := t.bot.config
+				config.mu.Lock()
+				if config.SlotThresholds == nil {
+					config.SlotThresholds = make(map[int]int)
+				}
+				if percent == 0 {
+					delete(config.SlotThresholds, slotNum)
+				} else {
+					config.SlotThresholds[slotNum] = percent
+				}
+				config.mu.Unlock()
+
+				updateChecks()
+
+				// Save configuration
+				t.bot.SaveState()
+
+				if percent == 0 {
+					LogInfo("Slot %d uses the global threshold", slotNum)
+				} else {
+					LogInfo("Updated slot %d threshold to: %d%%", slotNum, percent)
+				}
+			}
+		}(i*10, thresholdItems[i])
+	}
+
+	// Initialize checkmarks based on current config
+	updateChecks()
+	t.onSlotPageChange(updateChecks)
+}
+
 // handleSlotCooldownClick handles slot cooldown configuration menu clicks
 // This shows a submenu with cooldown time options
-func (t *TrayApp) handleSlotCooldownClick(slotNum int, menuItem *systray.MenuItem) {
+func (t *TrayApp) handleSlotCooldownClick(index int, menuItem *systray.MenuItem) {
 	// Define cooldown options (in milliseconds)
 	cooldowns := []struct {
 		label string
@@ -674,12 +977,27 @@ func (t *TrayApp) handleSlotCooldownClick(slotNum int, menuItem *systray.MenuIte
 		cooldownItems[i] = menuItem.AddSubMenuItemCheckbox(cd.label, "", false)
 	}
 
+	updateChecks := func() {
+		config := t.bot.config
+		config.mu.RLock()
+		currentCd := config.SlotCooldowns[t.slotNum(index)] // 0 = Disabled
+		config.mu.RUnlock()
+		for i, cd := range cooldowns {
+			if cd.ms == currentCd {
+				cooldownItems[i].Check()
+			} else {
+				cooldownItems[i].Uncheck()
+			}
+		}
+	}
+
 	// Start handlers for each cooldown option
 	for i, cd := range cooldowns {
 		go func(idx int, cooldownMs int, item *systray.MenuItem) {
 			for {
 				<-item.ClickedCh
 
+				slotNum := t.slotNum(index)
 				config := t.bot.config
 				config.mu.Lock()
 				if cooldownMs == 0 {
@@ -690,17 +1008,7 @@ func (t *TrayApp) handleSlotCooldownClick(slotNum int, menuItem *systray.MenuIte
 				config.mu.Unlock()
 
 				// Update checkmarks for this slot's cooldown options
-				currentCd, hasCd := config.SlotCooldowns[slotNum]
-				if !hasCd {
-					currentCd = 0
-				}
-				for j, cdo := range cooldowns {
-					if cdo.ms == currentCd {
-						cooldownItems[j].Check()
-					} else {
-						cooldownItems[j].Uncheck()
-					}
-				}
+				updateChecks()
 
 				// Save configuration
 				t.bot.SaveState()
@@ -715,16 +1023,350 @@ func (t *TrayApp) handleSlotCooldownClick(slotNum int, menuItem *systray.MenuIte
 	}
 
 	// Initialize checkmarks based on current config
+	updateChecks()
+	t.onSlotPageChange(updateChecks)
+}
+
+// updateMobFilterItems refreshes the mob filter submenu with recently seen names
+// and the whitelist/blacklist checkmarks
+func (t *TrayApp) updateMobFilterItems() {
 	config := t.bot.config
 	config.mu.RLock()
-	currentCd, hasCd := config.SlotCooldowns[slotNum]
+	readNames := config.MobNameOCR
+	whitelist := append([]string{}, config.MobWhitelist...)
+	blacklist := append([]string{}, config.MobBlacklist...)
 	config.mu.RUnlock()
-	if !hasCd {
-		currentCd = 0
+
+	if readNames {
+		t.mobNameOCRItem.Check()
+	} else {
+		t.mobNameOCRItem.Uncheck()
 	}
-	for i, cd := range cooldowns {
-		if cd.ms == currentCd {
-			cooldownItems[i].Check()
+
+	// Filtered names come first so they stay visible, then recently seen ones
+	names := append(append([]string{}, whitelist...), blacklist...)
+	for _, name := range t.bot.analyzer.RecentMobNames() {
+		if !MatchesMobName(name, names) {
+			names = append(names, name)
+		}
+	}
+
+	t.mobNamesMu.Lock()
+	defer t.mobNamesMu.Unlock()
+
+	for i := 0; i < 10; i++ {
+		if i >= len(names) {
+			t.mobNames[i] = ""
+			t.mobNameItems[i].Hide()
+			continue
+		}
+
+		name := names[i]
+		t.mobNames[i] = name
+		t.mobNameItems[i].SetTitle(name)
+		t.mobNameItems[i].Show()
+
+		if containsName(whitelist, name) {
+			t.mobWhitelistItems[i].Check()
+		} else {
+			t.mobWhitelistItems[i].Uncheck()
+		}
+		if containsName(blacklist, name) {
+			t.mobBlacklistItems[i].Check()
+		} else {
+			t.mobBlacklistItems[i].Uncheck()
+		}
+	}
+}
+
+// handleMobFilterClick toggles the name in slot index on the whitelist or blacklist
+func (t *TrayApp) handleMobFilterClick(index int, whitelist bool, menuItem *systray.MenuItem) {
+	for {
+		<-menuItem.ClickedCh
+
+		t.mobNamesMu.Lock()
+		name := t.mobNames[index]
+		t.mobNamesMu.Unlock()
+		if name == "" {
+			continue
+		}
+
+		config := t.bot.config
+		config.mu.Lock()
+		list := &config.MobBlacklist
+		listName := "blacklist"
+		if whitelist {
+			list = &config.MobWhitelist
+			listName = "whitelist"
+		}
+
+		// Toggle the name in the list
+		found := false
+		for i, entry := range *list {
+			if strings.EqualFold(entry, name) {
+				*list = append((*list)[:i], (*list)[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			*list = append(*list, name)
+		}
+		config.mu.Unlock()
+
+		// Update checkmarks
+		t.updateMobFilterItems()
+
+		// Save configuration
+		t.bot.SaveState()
+
+		if found {
+			LogInfo("Removed %q from mob %s", name, listName)
+		} else {
+			LogInfo("Added %q to mob %s", name, listName)
+		}
+	}
+}
+
+// handleMobNameOCRClick toggles mob name recognition without filters
+func (t *TrayApp) handleMobNameOCRClick() {
+	for {
+		<-t.mobNameOCRItem.ClickedCh
+
+		config := t.bot.config
+		config.mu.Lock()
+		config.MobNameOCR = !config.MobNameOCR
+		enabled := config.MobNameOCR
+		config.mu.Unlock()
+
+		// Update checkmarks
+		t.updateMobFilterItems()
+
+		// Save configuration
+		t.bot.SaveState()
+
+		LogInfo("Mob name reading enabled: %v", enabled)
+	}
+}
+
+// handleMobFilterClearClick clears the mob whitelist and blacklist
+func (t *TrayApp) handleMobFilterClearClick() {
+	for {
+		<-t.mobFilterClearItem.ClickedCh
+
+		config := t.bot.config
+		config.mu.Lock()
+		config.MobWhitelist = []string{}
+		config.MobBlacklist = []string{}
+		config.mu.Unlock()
+
+		// Update checkmarks
+		t.updateMobFilterItems()
+
+		// Save configuration
+		t.bot.SaveState()
+
+		LogInfo("Cleared mob whitelist and blacklist")
+	}
+}
+
+// containsName reports whether list contains name (case-insensitive)
+func containsName(list []string, name string) bool {
+	for _, entry := range list {
+		if strings.EqualFold(entry, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mobColorBound returns a pointer to one bound of a mob class color range
+func mobColorBound(colors *MobColorConfig, class, bound int) *int {
+	ranges := [3]*HSVBounds{&colors.Passive, &colors.Aggressive, &colors.Violet}
+	r := ranges[class]
+	fields := [6]*int{&r.HMin, &r.HMax, &r.SMin, &r.SMax, &r.VMin, &r.VMax}
+	return fields[bound]
+}
+
+// updateMobColorItems refreshes the mob color bound titles with current values
+func (t *TrayApp) updateMobColorItems() {
+	config := t.bot.config
+	config.mu.RLock()
+	colors := config.MobColors
+	config.mu.RUnlock()
+
+	for c := range mobColorClasses {
+		for b, name := range mobColorBounds {
+			t.mobColorBoundItems[c][b].SetTitle(fmt.Sprintf("%s: %d", name, *mobColorBound(&colors, c, b)))
+		}
+	}
+}
+
+// handleMobColorClick nudges one bound of a mob class color range.
+// Hue is clamped to 0-180 and may end up with HMin > HMax, which IdentifyMobs
+// treats as a wrap-around range (needed for red names).
+func (t *TrayApp) handleMobColorClick(class, bound, delta int, menuItem *systray.MenuItem) {
+	maxValue := 255
+	if bound < 2 {
+		maxValue = 180
+	}
+
+	for {
+		<-menuItem.ClickedCh
+
+		config := t.bot.config
+		config.mu.Lock()
+		value := mobColorBound(&config.MobColors, class, bound)
+		*value = Clamp(*value+delta, 0, maxValue)
+		newValue := *value
+		config.mu.Unlock()
+
+		// Update titles
+		t.updateMobColorItems()
+
+		// Save configuration
+		t.bot.SaveState()
+
+		LogInfo("Updated %s %s to: %d", mobColorClasses[class], mobColorBounds[bound], newValue)
+	}
+}
+
+// updateMobClickItems refreshes the mob click offset titles and mode checkmark
+func (t *TrayApp) updateMobClickItems() {
+	config := t.bot.config
+	config.mu.RLock()
+	offsetX, offsetY := config.MobClickOffsetX, config.MobClickOffsetY
+	height := config.MobClickOffsetMode == MobClickOffsetHeight
+	config.mu.RUnlock()
+
+	unit := "px"
+	if height {
+		unit = "%"
+		t.mobClickHeightItem.Check()
+	} else {
+		t.mobClickHeightItem.Uncheck()
+	}
+	t.mobClickOffsetItems[0].SetTitle(fmt.Sprintf("X: %d%s", offsetX, unit))
+	t.mobClickOffsetItems[1].SetTitle(fmt.Sprintf("Y: %d%s", offsetY, unit))
+}
+
+// handleMobClickOffsetClick nudges the X (axis 0) or Y (axis 1) mob click offset
+func (t *TrayApp) handleMobClickOffsetClick(axis, delta int, menuItem *systray.MenuItem) {
+	for {
+		<-menuItem.ClickedCh
+
+		config := t.bot.config
+		config.mu.Lock()
+		value := &config.MobClickOffsetX
+		if axis == 1 {
+			value = &config.MobClickOffsetY
+		}
+		*value += delta
+		newValue := *value
+		config.mu.Unlock()
+
+		// Update titles
+		t.updateMobClickItems()
+
+		// Save configuration
+		t.bot.SaveState()
+
+		LogInfo("Updated mob click offset %s to: %d", []string{"X", "Y"}[axis], newValue)
+	}
+}
+
+// handleMobClickModeClick toggles mob click offsets between pixels and percent of the nameplate height
+func (t *TrayApp) handleMobClickModeClick() {
+	for {
+		<-t.mobClickHeightItem.ClickedCh
+
+		config := t.bot.config
+		config.mu.Lock()
+		if config.MobClickOffsetMode == MobClickOffsetHeight {
+			config.MobClickOffsetMode = MobClickOffsetPixels
+		} else {
+			config.MobClickOffsetMode = MobClickOffsetHeight
 		}
+		mode := config.MobClickOffsetMode
+		config.mu.Unlock()
+
+		t.updateMobClickItems()
+
+		// Save configuration
+		t.bot.SaveState()
+
+		LogInfo("Mob click offset mode: %s", mode)
+	}
+}
+
+// updateBarColorItems shows whether calibrated bar colors are in use
+func (t *TrayApp) updateBarColorItems() {
+	config := t.bot.config
+	config.mu.RLock()
+	calibrated := len(config.BarColors)
+	config.mu.RUnlock()
+
+	if calibrated > 0 {
+		t.barColorResetItem.SetTitle(fmt.Sprintf("Reset to Defaults (%d calibrated)", calibrated))
+		t.barColorResetItem.Enable()
+	} else {
+		t.barColorResetItem.SetTitle("Reset to Defaults")
+		t.barColorResetItem.Disable()
+	}
+}
+
+// handleBarCalibrateClick samples the HP/MP/FP bar colors from the current
+// frame and stores them in config.BarColors. The bars must be full.
+func (t *TrayApp) handleBarCalibrateClick() {
+	for {
+		<-t.barCalibrateItem.ClickedCh
+
+		colors, err := t.bot.analyzer.CalibrateBars()
+		if err != nil {
+			LogWarn("Bar calibration failed: %v", err)
+			continue
+		}
+
+		config := t.bot.config
+		config.mu.Lock()
+		if config.BarColors == nil {
+			config.BarColors = make(map[string]HSVRange)
+		}
+		for name, colorRange := range colors {
+			config.BarColors[name] = colorRange
+		}
+		barColors := make(map[string]HSVRange, len(config.BarColors))
+		for name, colorRange := range config.BarColors {
+			barColors[name] = colorRange
+		}
+		config.mu.Unlock()
+
+		t.bot.analyzer.GetStats().SetBarColors(barColors)
+		t.updateBarColorItems()
+
+		// Save configuration
+		t.bot.SaveState()
+
+		LogInfo("Calibrated %d bar colors", len(colors))
+	}
+}
+
+// handleBarColorResetClick drops the calibrated bar colors
+func (t *TrayApp) handleBarColorResetClick() {
+	for {
+		<-t.barColorResetItem.ClickedCh
+
+		config := t.bot.config
+		config.mu.Lock()
+		config.BarColors = make(map[string]HSVRange)
+		config.mu.Unlock()
+
+		t.bot.analyzer.GetStats().SetBarColors(nil)
+		t.updateBarColorItems()
+
+		// Save configuration
+		t.bot.SaveState()
+
+		LogInfo("Bar colors reset to defaults")
 	}
 }
@@ -19,6 +19,7 @@
 //    - FormatDuration: Converts duration to human-readable string (e.g., "2m 30s")
 //    - FormatFloat: Formats floats with specified decimal places
 //    - Clamp/ClampFloat: Restricts values to min/max range
+//    - abs/min/max: Integer helpers shared by the analyzer and frame diffing
 //    - SafeGo: Launches goroutines with panic recovery
 //
 // Performance Monitoring:
@@ -105,6 +106,30 @@ func Clamp(value, min, max int) int {
 	return value
 }
 
+// abs returns absolute value of an integer
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// max returns the maximum of two integers
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// min returns the minimum of two integers
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 // ClampFloat restricts a float value between min and max
 func ClampFloat(value, min, max float64) float64 {
 	if value < min {
@@ -0,0 +1,144 @@
+// Package main - window.go
+//
+// Browser window size and placement.
+// The window size and position come from Config.WindowWidth/Height/X/Y. On
+// multi-monitor setups a saved position can end up off-screen (monitor
+// unplugged or rearranged), so positions are checked against the connected
+// displays and replaced by a window centered on the primary display when the
+// title bar would not be visible.
+//
+// Display bounds are only available on Windows (see display_windows.go), other
+// platforms use the configured position as is.
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	defaultWindowWidth  = 800
+	defaultWindowHeight = 600
+
+	// windowTitleBarHeight is the strip at the top of the window that must be
+	// on a display for the window to count as visible (it is what the user drags)
+	windowTitleBarHeight = 30
+)
+
+// WindowBounds is the browser window placement in screen coordinates
+type WindowBounds struct {
+	X, Y          int
+	Width, Height int
+	Positioned    bool // false = let the OS place the window
+}
+
+// WindowBoundsFromConfig reads the window settings, validating them against
+// the connected displays
+func WindowBoundsFromConfig(config *Config) WindowBounds {
+	config.mu.RLock()
+	bounds := WindowBounds{
+		Width:  config.WindowWidth,
+		Height: config.WindowHeight,
+	}
+	// Secondary displays left of/above the primary one have negative coordinates,
+	// so an unset position is nil rather than a negative sentinel
+	if config.WindowX != nil && config.WindowY != nil {
+		bounds.X = *config.WindowX
+		bounds.Y = *config.WindowY
+		bounds.Positioned = true
+	}
+	config.mu.RUnlock()
+
+	return validateWindowBounds(bounds)
+}
+
+// validateWindowBounds fixes invalid sizes and moves off-screen windows to the
+// center of the primary display
+func validateWindowBounds(bounds WindowBounds) WindowBounds {
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		LogWarn("Invalid window size %dx%d, using %dx%d", bounds.Width, bounds.Height, defaultWindowWidth, defaultWindowHeight)
+		bounds.Width = defaultWindowWidth
+		bounds.Height = defaultWindowHeight
+	}
+
+	if !bounds.Positioned {
+		return bounds
+	}
+
+	titleBar := image.Rect(bounds.X, bounds.Y, bounds.X+bounds.Width, bounds.Y+windowTitleBarHeight)
+	visible, known := rectOnDisplay(titleBar)
+	if !known || visible {
+		return bounds
+	}
+
+	primary, ok := primaryDisplayBounds()
+	if !ok {
+		LogWarn("Window position (%d,%d) is off-screen, letting the OS place the window", bounds.X, bounds.Y)
+		bounds.Positioned = false
+		return bounds
+	}
+
+	bounds.Width = min(bounds.Width, primary.Dx())
+	bounds.Height = min(bounds.Height, primary.Dy())
+	bounds.X = primary.Min.X + (primary.Dx()-bounds.Width)/2
+	bounds.Y = primary.Min.Y + (primary.Dy()-bounds.Height)/2
+	LogWarn("Window position is off-screen, centering on the primary display at (%d,%d)", bounds.X, bounds.Y)
+	return bounds
+}
+
+// allocatorOptions returns the chromedp options applying the window bounds
+func (wb WindowBounds) allocatorOptions() []chromedp.ExecAllocatorOption {
+	opts := []chromedp.ExecAllocatorOption{chromedp.WindowSize(wb.Width, wb.Height)}
+	if wb.Positioned {
+		opts = append(opts, chromedp.Flag("window-position", fmt.Sprintf("%d,%d", wb.X, wb.Y)))
+	}
+	return opts
+}
+
+// SetWindowBounds moves and resizes the browser window at runtime.
+// The bounds are validated like the startup bounds.
+func (b *Browser) SetWindowBounds(bounds WindowBounds) error {
+	if b.ctx == nil || b.ctx.Err() != nil {
+		return fmt.Errorf("browser context is invalid")
+	}
+
+	bounds = validateWindowBounds(bounds)
+
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		windowID, _, err := cdpbrowser.GetWindowForTarget().Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get browser window: %w", err)
+		}
+
+		// Size and position cannot be changed while maximized/minimized
+		if err := cdpbrowser.SetWindowBounds(windowID, &cdpbrowser.Bounds{WindowState: cdpbrowser.WindowStateNormal}).Do(ctx); err != nil {
+			return fmt.Errorf("failed to restore browser window: %w", err)
+		}
+
+		target := &cdpbrowser.Bounds{
+			Width:       int64(bounds.Width),
+			Height:      int64(bounds.Height),
+			WindowState: cdpbrowser.WindowStateNormal,
+		}
+		if bounds.Positioned {
+			target.Left = int64(bounds.X)
+			target.Top = int64(bounds.Y)
+		}
+		return cdpbrowser.SetWindowBounds(windowID, target).Do(ctx)
+	}))
+	if err != nil {
+		return err
+	}
+
+	b.window = bounds
+	LogInfo("Browser window set to %dx%d at (%d,%d)", bounds.Width, bounds.Height, bounds.X, bounds.Y)
+	return nil
+}
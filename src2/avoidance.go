@@ -0,0 +1,108 @@
+// Package main - avoidance.go
+//
+// This file implements the avoidance list of spots where targets could not be reached.
+// The list is cleared with the Clear Avoidance List action (see main.go).
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AvoidanceList holds heatmap cells (world positions) of targets that could not
+// be reached, such as a mob spawn behind a cliff. A cell is the target's estimated
+// position (player cell plus its offset on screen), keyed like the heatmap, so only
+// mobs seen there are ignored. Entries expire after TTL. Cells are relative to the
+// session's start position, so the list is not loaded back after a restart; the
+// file only mirrors the current list.
+type AvoidanceList struct {
+	Path string        // File the list is persisted to ("" = not persisted)
+	TTL  time.Duration // Time an avoided cell stays on the list (0 = disabled)
+
+	Cells map[string]time.Time // Expiry time by cell key
+
+	mu sync.Mutex
+}
+
+// clearAvoidanceFile is the control file that clears the avoidance list when it
+// appears next to stat.json (works on every platform, unlike the signal)
+const clearAvoidanceFile = "clear_avoidance"
+
+// NewAvoidanceList creates an empty avoidance list, overwriting the previous session's file
+func NewAvoidanceList(path string, ttl time.Duration) *AvoidanceList {
+	a := &AvoidanceList{
+		Path:  path,
+		TTL:   ttl,
+		Cells: make(map[string]time.Time),
+	}
+	a.save()
+	return a
+}
+
+// Add puts a cell on the list for TTL and saves the list
+func (a *AvoidanceList) Add(cell image.Point) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.TTL <= 0 {
+		return nil
+	}
+	a.Cells[cellKey(cell)] = time.Now().Add(a.TTL)
+	return a.save()
+}
+
+// Avoided reports whether a cell is on the list and not expired
+func (a *AvoidanceList) Avoided(cell image.Point) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.TTL <= 0 {
+		return false
+	}
+	expiry, ok := a.Cells[cellKey(cell)]
+	return ok && time.Now().Before(expiry)
+}
+
+// Clear removes every cell from the list and saves it
+func (a *AvoidanceList) Clear() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.Cells = make(map[string]time.Time)
+	return a.save()
+}
+
+// AvoidanceClearRequested reports whether the clear control file exists in dir, removing it
+func AvoidanceClearRequested(dir string) bool {
+	return os.Remove(filepath.Join(dir, clearAvoidanceFile)) == nil
+}
+
+// save drops expired cells and writes the list to Path (caller holds the lock)
+func (a *AvoidanceList) save() error {
+	now := time.Now()
+	for key, expiry := range a.Cells {
+		if !now.Before(expiry) {
+			delete(a.Cells, key)
+		}
+	}
+
+	if a.Path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(a.Cells, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal avoidance list: %w", err)
+	}
+
+	if err := os.WriteFile(a.Path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write avoidance file: %w", err)
+	}
+
+	return nil
+}
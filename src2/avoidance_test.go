@@ -0,0 +1,42 @@
+package main
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAvoidanceListNotReloaded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "avoidance.json")
+	cell := image.Pt(3, -2)
+
+	previous := NewAvoidanceList(path, time.Hour)
+	if err := previous.Add(cell); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Cells are relative to the session's start position, a new session starts empty
+	next := NewAvoidanceList(path, time.Hour)
+	if next.Avoided(cell) {
+		t.Error("cell from the previous session is still avoided")
+	}
+}
+
+func TestAvoidanceClearRequested(t *testing.T) {
+	dir := t.TempDir()
+	if AvoidanceClearRequested(dir) {
+		t.Fatal("clear requested without the control file")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, clearAvoidanceFile), nil, 0644); err != nil {
+		t.Fatalf("failed to create control file: %v", err)
+	}
+	if !AvoidanceClearRequested(dir) {
+		t.Fatal("control file not picked up")
+	}
+	if AvoidanceClearRequested(dir) {
+		t.Error("control file picked up twice")
+	}
+}
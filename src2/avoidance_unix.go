@@ -0,0 +1,12 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// clearAvoidanceSignals clear the avoidance list while the bot runs (kill -USR1 <pid>),
+// creating the clear_avoidance file next to stat.json works too
+var clearAvoidanceSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// clearAvoidanceSignals is empty on Windows (no user signals), create the
+// clear_avoidance file next to stat.json instead
+var clearAvoidanceSignals []os.Signal
@@ -0,0 +1,47 @@
+// Package main - barfill.go
+//
+// This file measures how far a status bar is filled from its color mask.
+// The debug programs (debug_status.go, debug_target.go) use it too, so run them
+// together with this file: go run -tags ignore debug_status.go debug_screencast.go barfill.go
+package main
+
+import (
+	"gocv.io/x/gocv"
+)
+
+// minBarFillConfidence is the fraction of rows that must be filled at the fill
+// edge for a reading to be trusted; lower values are usually stray pixels
+const minBarFillConfidence = 0.3
+
+// BarFill is the result of measuring a bar mask
+type BarFill struct {
+	Width      int     // Columns up to and including the rightmost filled column
+	Confidence float64 // Fraction of rows filled at the fill edge (0-1)
+}
+
+// Reliable reports whether the fill edge is solid enough to use the reading
+func (b BarFill) Reliable() bool {
+	return b.Width > 0 && b.Confidence >= minBarFillConfidence
+}
+
+// measureBarFill finds the rightmost filled column of a binary (0/255) mask.
+// Columns are summed with a single Reduce instead of scanning every pixel.
+func measureBarFill(mask gocv.Mat) BarFill {
+	if mask.Empty() || mask.Rows() == 0 {
+		return BarFill{}
+	}
+
+	colSums := gocv.NewMat()
+	defer colSums.Close()
+	gocv.Reduce(mask, &colSums, 0, gocv.ReduceSum, gocv.MatTypeCV32F)
+
+	for x := colSums.Cols() - 1; x >= 0; x-- {
+		if sum := colSums.GetFloatAt(0, x); sum > 0 {
+			return BarFill{
+				Width:      x + 1,
+				Confidence: float64(sum) / (255 * float64(mask.Rows())),
+			}
+		}
+	}
+	return BarFill{}
+}
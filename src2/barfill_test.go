@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// scanBarFill is the per-pixel reader measureBarFill replaced: it scans columns
+// from the right and stops at the first one with a filled pixel
+func scanBarFill(mask gocv.Mat) int {
+	for x := mask.Cols() - 1; x >= 0; x-- {
+		for y := 0; y < mask.Rows(); y++ {
+			if mask.GetUCharAt(y, x) > 0 {
+				return x + 1
+			}
+		}
+	}
+	return 0
+}
+
+// barMask returns a width x height mask filled from the left up to fill columns
+func barMask(width, height, fill int) gocv.Mat {
+	mask := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 0, 0, 0), height, width, gocv.MatTypeCV8U)
+	if fill > 0 {
+		gocv.Rectangle(&mask, image.Rect(0, 0, fill-1, height-1), color.RGBA{255, 255, 255, 0}, -1)
+	}
+	return mask
+}
+
+func TestMeasureBarFill(t *testing.T) {
+	tests := []struct {
+		name           string
+		fill           int
+		wantConfidence float64
+	}{
+		{"empty", 0, 0},
+		{"partial", 120, 1},
+		{"full", 300, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask := barMask(300, 12, tt.fill)
+			defer mask.Close()
+
+			got := measureBarFill(mask)
+			if got.Width != tt.fill {
+				t.Errorf("Width = %d, want %d", got.Width, tt.fill)
+			}
+			if got.Confidence != tt.wantConfidence {
+				t.Errorf("Confidence = %v, want %v", got.Confidence, tt.wantConfidence)
+			}
+			if scanned := scanBarFill(mask); scanned != got.Width {
+				t.Errorf("per-pixel reader = %d, Reduce reader = %d", scanned, got.Width)
+			}
+		})
+	}
+}
+
+func TestMeasureBarFillStrayPixel(t *testing.T) {
+	// One stray pixel right of the fill edge: the width follows it, but the
+	// confidence shows the edge is not a real fill
+	mask := barMask(300, 12, 100)
+	defer mask.Close()
+	mask.SetUCharAt(5, 250, 255)
+
+	got := measureBarFill(mask)
+	if got.Width != 251 {
+		t.Errorf("Width = %d, want 251", got.Width)
+	}
+	if got.Reliable() {
+		t.Errorf("Reliable() = true for a single stray pixel (confidence %v)", got.Confidence)
+	}
+}
+
+// benchmarkFills are fill levels of a 300x12 bar, the per-pixel reader gets
+// slower the emptier the bar is
+var benchmarkFills = []int{0, 30, 150, 300}
+
+func BenchmarkBarFillPerPixel(b *testing.B) {
+	for _, fill := range benchmarkFills {
+		b.Run(fmt.Sprintf("fill=%d", fill), func(b *testing.B) {
+			mask := barMask(300, 12, fill)
+			defer mask.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				scanBarFill(mask)
+			}
+		})
+	}
+}
+
+func BenchmarkBarFillReduce(b *testing.B) {
+	for _, fill := range benchmarkFills {
+		b.Run(fmt.Sprintf("fill=%d", fill), func(b *testing.B) {
+			mask := barMask(300, 12, fill)
+			defer mask.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				measureBarFill(mask)
+			}
+		})
+	}
+}
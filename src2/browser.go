@@ -26,7 +26,20 @@ type Browser struct {
 	cancel      context.CancelFunc
 	allocCtx    context.Context
 	allocCancel context.CancelFunc
-	frameChan   chan *image.RGBA
+	frameChan   chan *Frame
+	lastFrame   time.Time // Time the last screencast frame was received
+	stalled     bool      // True while falling back to screenshots
+}
+
+// Frame is a captured game frame
+type Frame struct {
+	Image *image.RGBA
+	Time  time.Time // Time the frame was received from the browser
+}
+
+// Age returns how old the frame is
+func (f *Frame) Age() time.Duration {
+	return time.Since(f.Time)
 }
 
 // EvalJS contains the JavaScript code to inject into the game page
@@ -147,7 +160,7 @@ function setInputChat(text) {
 // NewBrowser creates a new browser instance
 func NewBrowser() *Browser {
 	return &Browser{
-		frameChan: make(chan *image.RGBA, 1), // Buffer of 1 to hold latest frame
+		frameChan: make(chan *Frame, 1), // Buffer of 1 to hold latest frame
 	}
 }
 
@@ -211,18 +224,12 @@ func (b *Browser) Start(cfg *Config) error {
 
 	// Start screencast after page loads
 	cfg.Log("Starting screencast stream...")
-	err = chromedp.Run(b.ctx,
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			return page.StartScreencast().
-				WithFormat("jpeg").
-				WithQuality(70).
-				Do(ctx)
-		}),
-	)
+	err = b.startScreencast(cfg)
 	if err != nil {
 		cfg.Log("Failed to start screencast: %v", err)
 		return err
 	}
+	b.lastFrame = time.Now()
 
 	// Inject JavaScript
 	err = b.InjectJS()
@@ -234,6 +241,34 @@ func (b *Browser) Start(cfg *Config) error {
 	return nil
 }
 
+// startScreencast starts the screencast stream in the configured format.
+//
+// PNG frames are lossless, so bar and name colors match the HSV ranges exactly,
+// but they are several times larger than JPEG and cost more CPU to encode in the
+// browser and decode here. JPEG saves CPU and bandwidth at the cost of
+// compression artifacts that shift colors, especially around thin bars and text.
+// Both formats go through image.Decode, which has both decoders registered.
+func (b *Browser) startScreencast(cfg *Config) error {
+	stat := cfg.Snapshot()
+	format := stat.Settings.ScreencastFormat
+	if format != ScreencastFormatJPEG && format != ScreencastFormatPNG {
+		cfg.Log("Unknown screencast format %q, using %s", format, ScreencastFormatPNG)
+		format = ScreencastFormatPNG
+	}
+
+	screencast := page.StartScreencast().WithFormat(page.ScreencastFormat(format))
+	if format == ScreencastFormatJPEG {
+		screencast = screencast.WithQuality(int64(max(0, min(stat.Settings.ScreencastQuality, 100))))
+	}
+	cfg.Log("Screencast format: %s", format)
+
+	return chromedp.Run(b.ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return screencast.Do(ctx)
+		}),
+	)
+}
+
 // setupScreencastListener sets up the event listener for screencast frames
 func (b *Browser) setupScreencastListener(cfg *Config) {
 	frameCount := 0
@@ -261,17 +296,8 @@ func (b *Browser) setupScreencastListener(cfg *Config) {
 					return
 				}
 
-				// Convert to RGBA
-				bounds := img.Bounds()
-				rgba := image.NewRGBA(bounds)
-				for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-					for x := bounds.Min.X; x < bounds.Max.X; x++ {
-						rgba.Set(x, y, img.At(x, y))
-					}
-				}
-
 				// Send frame to channel (blocking until Capture() takes it)
-				b.frameChan <- rgba
+				b.frameChan <- &Frame{Image: toRGBA(img), Time: time.Now()}
 
 				// After frame is consumed by Capture(), acknowledge to Chrome
 				// This way Chrome won't send next frame until this one is consumed
@@ -281,18 +307,66 @@ func (b *Browser) setupScreencastListener(cfg *Config) {
 	})
 }
 
-// Capture returns the latest frame from the screencast stream
-func (b *Browser) Capture() (*image.RGBA, error) {
+// Capture returns the latest frame from the screencast stream.
+// If no screencast frame arrived within StaleFrameTimeout (e.g. the tab is
+// backgrounded and throttled), it falls back to a direct screenshot.
+func (b *Browser) Capture(cfg *Config) (*Frame, error) {
 	if b.ctx == nil || b.ctx.Err() != nil {
 		return nil, fmt.Errorf("browser context is invalid")
 	}
 
 	select {
 	case frame := <-b.frameChan:
+		b.lastFrame = frame.Time
+		if b.stalled {
+			cfg.Log("Screencast frames resumed")
+			b.stalled = false
+		}
 		return frame, nil
 	default:
+	}
+
+	timeout := time.Duration(cfg.Snapshot().Settings.StaleFrameTimeout) * time.Millisecond
+	if timeout <= 0 || time.Since(b.lastFrame) < timeout {
 		return nil, fmt.Errorf("no frame available")
 	}
+
+	if !b.stalled {
+		cfg.Log("Warning: no screencast frame for %v, falling back to screenshots", time.Since(b.lastFrame).Round(time.Millisecond))
+		b.stalled = true
+	}
+	return b.captureScreenshot()
+}
+
+// captureScreenshot takes a screenshot via CDP, bypassing the screencast
+func (b *Browser) captureScreenshot() (*Frame, error) {
+	var buf []byte
+	ctx, cancel := context.WithTimeout(b.ctx, 5*time.Second)
+	defer cancel()
+
+	err := chromedp.Run(ctx, chromedp.CaptureScreenshot(&buf))
+	if err != nil {
+		return nil, fmt.Errorf("screenshot failed: %v", err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %v", err)
+	}
+
+	return &Frame{Image: toRGBA(img), Time: time.Now()}, nil
+}
+
+// toRGBA converts an image to RGBA
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			rgba.Set(x, y, img.At(x, y))
+		}
+	}
+	return rgba
 }
 
 // SaveCookie saves browser cookies to config
@@ -362,14 +436,7 @@ func (b *Browser) Refresh(cfg *Config) error {
 
 	// Restart screencast after reload
 	cfg.Log("Restarting screencast stream...")
-	err = chromedp.Run(b.ctx,
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			return page.StartScreencast().
-				WithFormat("jpeg").
-				WithQuality(70).
-				Do(ctx)
-		}),
-	)
+	err = b.startScreencast(cfg)
 	if err != nil {
 		cfg.Log("Failed to restart screencast: %v", err)
 		return err
@@ -421,6 +488,23 @@ func (b *Browser) Eval(js string) error {
 	)
 }
 
+// CheckCanvasExists reports whether the game canvas is in the page (false while
+// the page is blank or still loading)
+func (b *Browser) CheckCanvasExists() bool {
+	if b.ctx == nil || b.ctx.Err() != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, 2*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := chromedp.Run(ctx,
+		chromedp.Evaluate(`document.querySelector('canvas') !== null`, &exists),
+	)
+	return err == nil && exists
+}
+
 // SimpleClick performs a simple click at the given coordinates
 func (b *Browser) SimpleClick(x, y int) error {
 	js := fmt.Sprintf("mouseEvent('moveClick', %d, %d);", x, y)
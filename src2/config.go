@@ -2,16 +2,19 @@
 //
 // This file manages configuration, status, and cookie data for the bot.
 // It handles three JSON files:
-// - stat.json: Configuration (read-only, read every second)
+// - stat.json: Configuration (read-only, reloaded when changed, see reload.go)
 // - cookie.json: Browser cookies (read at startup, write at exit)
 // - status.json: Current status (write-only, write every second)
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -23,6 +26,7 @@ const (
 	SlotTypeHeal       = 3  // Heal skill
 	SlotTypeRescue     = 4  // Rescue/Resurrection skill
 	SlotTypeBoard      = 5  // Board/Mount skill
+	SlotTypeTeleport   = 6  // Teleport/return scroll (panic action)
 	SlotTypeFood       = 11 // HP food
 	SlotTypePill       = 12 // HP pill
 	SlotTypeMPRestore  = 21 // MP restore
@@ -52,41 +56,151 @@ type Slot struct {
 
 // AttackSettings holds attack-related configuration
 type AttackSettings struct {
-	AttackMinHP            int `json:"attackMinHP"`            // Minimum HP to attack
-	DefeatInterval         int `json:"defeatInterval"`         // Wait time after killing a mob (ms)
-	ObstacleThresholdTime  int `json:"obstacleThresholdTime"`  // Time threshold to detect obstacle (ms)
-	ObstacleAvoidCount     int `json:"obstacleAvoidCount"`     // Max obstacle avoidance attempts
-	ObstacleCoolDown       int `json:"obstacleCoolDown"`       // Cooldown between obstacle attempts (ms)
-	EscapeHP               int `json:"escapeHp"`               // HP threshold to escape (%)
-	MaxTime                int `json:"maxTime"`                // Max attack time before giving up (seconds)
+	AttackMinHP           int    `json:"attackMinHP"`           // Minimum HP to attack
+	DefeatInterval        int    `json:"defeatInterval"`        // Wait time after killing a mob (ms)
+	ObstacleThresholdTime int    `json:"obstacleThresholdTime"` // Time threshold to detect obstacle (ms)
+	ObstacleAvoidCount    int    `json:"obstacleAvoidCount"`    // Max obstacle avoidance attempts
+	ObstacleCoolDown      int    `json:"obstacleCoolDown"`      // Cooldown between obstacle attempts (ms)
+	EscapeHP              int    `json:"escapeHp"`              // HP threshold to escape (%)
+	CriticalHP            int    `json:"criticalHp"`            // HP below which a pill is used and the bot escapes at once (%, 0 = disabled)
+	CriticalHPRate        int    `json:"criticalHpRate"`        // HP loss rate that triggers the same emergency (%/s, 0 = disabled)
+	MaxTime               int    `json:"maxTime"`               // Max attack time before giving up (seconds)
+	PanicMobCount         int    `json:"panicMobCount"`         // Aggressive mobs on screen that trigger PanicAction (0 = disabled)
+	PanicAction           string `json:"panicAction"`           // "escape", "teleport" (teleport slot) or "continue"
+	SmartEscape           bool   `json:"smartEscape"`           // Turn toward the minimap sector with the fewest monsters before escaping (false = straight ahead)
+	EscapeMaxTime         int    `json:"escapeMaxTime"`         // Max escape time including the wait for EscapeResumeHP (seconds, 0 = unlimited)
+	EscapeResumeHP        int    `json:"escapeResumeHp"`        // HP to recover after escaping before farming resumes (%, 0 = resume at once)
 }
 
+// Panic actions (AttackSettings.PanicAction).
+// This bot has no tray: PanicMobCount and PanicAction are set in stat.json
+// ("attack" section), which is reloaded while the bot runs (see reload.go).
+const (
+	PanicActionEscape   = "escape"   // Run away (Escaping stage)
+	PanicActionTeleport = "teleport" // Use a teleport slot, escape if none is ready
+	PanicActionContinue = "continue" // Keep fighting
+)
+
 // Settings holds general bot settings
 type Settings struct {
-	BuffInterval   int    `json:"buffInterval"`   // Wait time after using a buff (ms)
-	DeathConfirm   int    `json:"deathConfirm"`   // Interval to press enter after death (ms)
-	ShoutMessage   string `json:"shoutMessage"`   // Shout message content
-	ShoutInterval  int    `json:"shoutInterval"`  // Shout interval (seconds)
-	WatchDogTime   int    `json:"watchDogTime"`   // Watchdog timeout (seconds)
-	WatchDogRetry  int    `json:"watchDogRetry"`  // Max watchdog retry attempts
+	BuffInterval      int    `json:"buffInterval"`      // Wait time after using a buff (ms)
+	DeathConfirm      int    `json:"deathConfirm"`      // Interval to press enter after death (ms)
+	ShoutMessage      string `json:"shoutMessage"`      // Shout message content
+	ShoutInterval     int    `json:"shoutInterval"`     // Shout interval (seconds)
+	WatchDogTime      int    `json:"watchDogTime"`      // Watchdog timeout (seconds)
+	WatchDogRetry     int    `json:"watchDogRetry"`     // Max watchdog retry attempts
+	NavigateTime      int    `json:"navigateTime"`      // Max time spent navigating before searching again (seconds)
+	NavigateMove      int    `json:"navigateMove"`      // Time to move forward after each turn while navigating (ms)
+	StuckTime         int    `json:"stuckTime"`         // Time the minimap may stay still while moving forward before turning and jumping (ms, 0 = disabled)
+	StaleFrameTimeout int    `json:"staleFrameTimeout"` // Max screencast frame age before falling back to screenshots (ms, 0 = disabled)
+	ScreencastFormat  string `json:"screencastFormat"`  // Screencast frame format: "png" (exact colors) or "jpeg" (less CPU/bandwidth)
+	ScreencastQuality int    `json:"screencastQuality"` // JPEG quality 0-100 (ignored for png)
+	OfflineRecovery   string `json:"offlineRecovery"`   // First offline recovery strategy: "refresh", "reload-soft" or "reconnect-button"
+	ReconnectTemplate string `json:"reconnectTemplate"` // Template image of the in-game reconnect button
+	LoginTemplate     string `json:"loginTemplate"`     // Template image of the login screen button to click ("" = not detected)
+	StartTemplate     string `json:"startTemplate"`     // Template image of the character select Start button ("" = not detected)
+	CharacterTemplate string `json:"characterTemplate"` // Template image of the character to select before Start ("" = keep the selection)
+	DeathTemplate     string `json:"deathTemplate"`     // Template image of the respawn dialog confirming death ("" = HP only)
+	HeatmapHalfLife   int    `json:"heatmapHalfLife"`   // Time for heatmap encounter counts to decay to half (minutes)
+	HeatmapRadius     int    `json:"heatmapRadius"`     // Max heatmap cells away to navigate to
+	AvoidanceTTL      int    `json:"avoidanceTTL"`      // Time a spot with an unreachable target is avoided (minutes, 0 = disabled)
+	DumpLimit         int    `json:"dumpLimit"`         // Max detection dumps kept in dumps/ when debug is on (0 = unlimited)
+	TargetingMode     string `json:"targetingMode"`     // How mobs are selected: "click" (click the name) or "nearestkey" (TargetKey)
+	TargetKey         string `json:"targetKey"`         // In-game select-nearest-target key used in nearestkey mode
+	TargetKeyRetries  int    `json:"targetKeyRetries"`  // Consecutive TargetKey presses selecting nothing before falling back to clicks
+
+	ActionHistorySize   int  `json:"actionHistorySize"`   // Actions kept in memory before they are appended to the actions CSV (0 = history disabled)
+	ActionHistoryRotate bool `json:"actionHistoryRotate"` // Start a new actions CSV each session, renaming the previous one
+
+	ReturnToFarmAfterDeath bool      `json:"returnToFarmAfterDeath"` // Walk back to the spot of death after respawning
+	ReturnMaxTime          int       `json:"returnMaxTime"`          // Max time spent walking back after respawning (seconds)
+	RespawnPoint           []float64 `json:"respawnPoint"`           // Respawn point as heatmap position [x, y] (status.json player.position there, empty = unknown)
+}
+
+// Targeting modes (Settings.TargetingMode)
+const (
+	TargetingModeClick      = "click"
+	TargetingModeNearestKey = "nearestkey"
+)
+
+// Screencast frame formats (Settings.ScreencastFormat)
+const (
+	ScreencastFormatPNG  = "png"
+	ScreencastFormatJPEG = "jpeg"
+)
+
+// Offline recovery strategies, ordered from least to most invasive.
+// Repeated failures escalate to the next strategy.
+const (
+	OfflineRecoveryReloadSoft      = "reload-soft"      // Press Enter/Escape only, no navigation
+	OfflineRecoveryReconnectButton = "reconnect-button" // Click the in-game reconnect button
+	OfflineRecoveryRefresh         = "refresh"          // Reload the page (may lose the session)
+)
+
+// offlineRecoveryOrder is the escalation order of offline recovery strategies
+var offlineRecoveryOrder = []string{
+	OfflineRecoveryReloadSoft,
+	OfflineRecoveryReconnectButton,
+	OfflineRecoveryRefresh,
+}
+
+// DetectionRegions holds the frame regions each detection is limited to.
+// Negative values are relative to the right/bottom edge of the frame.
+type DetectionRegions struct {
+	StatusBar ROIArea `json:"statusBar"` // Player HP/MP/FP bars
+	TargetBar ROIArea `json:"targetBar"` // Target HP/MP bars
+	MobArea   ROIArea `json:"mobArea"`   // Mob names
+	Minimap   ROIArea `json:"minimap"`   // Minimap (navigation)
+}
+
+// DefaultDetectionRegions returns the default regions for the game UI layout
+func DefaultDetectionRegions() DetectionRegions {
+	return DetectionRegions{
+		StatusBar: ROIArea{MinX: 0, MinY: 0, MaxX: 500, MaxY: 350},
+		TargetBar: ROIArea{MinX: 400, MinY: 200, MaxX: -400, MaxY: 200},
+		MobArea:   ROIArea{MinX: 0, MinY: 0, MaxX: -1, MaxY: -100}, // Full screen except bottom 100px
+		Minimap:   ROIArea{MinX: -165, MinY: 15, MaxX: -15, MaxY: 165},
+	}
+}
+
+// Merge overrides the regions with every region set in other
+func (r *DetectionRegions) Merge(other DetectionRegions) {
+	if !other.StatusBar.IsZero() {
+		r.StatusBar = other.StatusBar
+	}
+	if !other.TargetBar.IsZero() {
+		r.TargetBar = other.TargetBar
+	}
+	if !other.MobArea.IsZero() {
+		r.MobArea = other.MobArea
+	}
+	if !other.Minimap.IsZero() {
+		r.Minimap = other.Minimap
+	}
 }
 
 // Stat holds configuration data (read from stat.json)
 type Stat struct {
-	Enable         bool           `json:"enable"`     // Whether main program is running
-	Restorer       bool           `json:"restorer"`   // Whether to perform recovery
-	Detect         bool           `json:"detect"`     // Whether to auto-detect mobs
-	Navigate       bool           `json:"navigate"`   // Whether navigation is enabled
-	Debug          bool           `json:"debug"`      // Whether to save debug screenshots
-	Type           int            `json:"type"`       // 0=disable, 1=farming, 2=support, 3=auto shout
-	Interval       int            `json:"interval"`   // Frame interval in milliseconds
-	Slots          []Slot         `json:"slots"`      // Slot configurations
-	Attack         AttackSettings `json:"attack"`     // Attack settings
-	Settings       Settings       `json:"settings"`   // General settings
-	StatusPath     string         `json:"status"`     // Status file path
-	CookiesPath    string         `json:"cookies"`    // Cookies file path
-	LogPath        string         `json:"log"`        // Log file path
-	BrowserLogPath string         `json:"browserLog"` // Browser log file path
+	SchemaVersion int `json:"schemaVersion"` // Layout version of stat.json (see decodeStat)
+
+	Enable         bool             `json:"enable"`     // Whether main program is running (see Config.IsEnabled)
+	Restorer       bool             `json:"restorer"`   // Whether to perform recovery
+	Detect         bool             `json:"detect"`     // Whether to auto-detect mobs
+	Navigate       bool             `json:"navigate"`   // Whether navigation is enabled
+	Debug          bool             `json:"debug"`      // Whether to save debug screenshots and status bar masks (dumps/bars)
+	Type           int              `json:"type"`       // 0=disable, 1=farming, 2=support, 3=auto shout
+	Interval       int              `json:"interval"`   // Frame interval in milliseconds
+	Slots          []Slot           `json:"slots"`      // Slot configurations
+	Attack         AttackSettings   `json:"attack"`     // Attack settings
+	Settings       Settings         `json:"settings"`   // General settings
+	Regions        DetectionRegions `json:"regions"`    // Detection regions
+	StatusPath     string           `json:"status"`     // Status file path
+	HeatmapPath    string           `json:"heatmap"`    // Mob density heatmap file path
+	AvoidancePath  string           `json:"avoidance"`  // Avoided spots file path
+	ActionsPath    string           `json:"actions"`    // Action history CSV file path ("" = not exported)
+	CookiesPath    string           `json:"cookies"`    // Cookies file path
+	LogPath        string           `json:"log"`        // Log file path
+	BrowserLogPath string           `json:"browserLog"` // Browser log file path
 }
 
 // Cookie represents a browser cookie
@@ -113,6 +227,7 @@ type PlayerStatus struct {
 	LastKilledTime time.Time `json:"-"`            // Internal: last kill time
 	LastKilledMS   int       `json:"lastKilledTime"` // JSON: time since last kill (ms)
 	Stage          string    `json:"stage"`
+	Position       [2]int    `json:"position"` // Estimated heatmap position (minimap pixels)
 }
 
 // TargetStatus holds target (mob) status information
@@ -169,21 +284,36 @@ type Status struct {
 	Player       PlayerStatus            `json:"player"`
 	Target       *TargetStatus           `json:"target"`   // nil if no target selected
 	Attack       AttackStatus            `json:"attack"`
-	Actions      []string                `json:"actions"`  // Last 10 actions
+	Actions      []string                `json:"actions"`  // Last statusActionCount actions
 	Cooldown     Cooldown                `json:"-"`        // Internal cooldown (not serialized)
 	CooldownJSON CooldownJSON            `json:"cooldown"` // JSON representation of cooldown
 	Mobs         []string                `json:"mobs"`     // List of detected mobs (format: "(x,y,w,h,type)")
+	TargetMob    int                     `json:"targetMob"` // Index in Mobs of the mob clicked last frame (-1 = none)
 	WaitCtx      map[string]*WaitContext `json:"-"`        // Wait contexts for state machine (not serialized)
 }
 
+// statusActionCount is the number of recent actions shown in status.json
+const statusActionCount = 10
+
+// ActionRecord is an action history entry
+type ActionRecord struct {
+	Time   time.Time
+	Action string
+}
+
 // Config is the main configuration object
 type Config struct {
-	Stat           Stat      // Configuration data
-	Status         Status    // Current status
-	Cookies        []Cookie  // Browser cookies
-	LogFile        *os.File  // Log file handle
-	BrowserLogFile *os.File  // Browser log file handle
-	StatPath       string    // Path to stat.json
+	Stat           Stat           // Configuration data
+	Status         Status         // Current status
+	Cookies        []Cookie       // Browser cookies
+	LogFile        *os.File       // Log file handle
+	BrowserLogFile *os.File       // Browser log file handle
+	StatPath       string         // Path to stat.json
+	statModTime    time.Time      // Modification time of the loaded stat.json
+	disabled       bool           // Stopped at runtime by Disable (kept across reloads, unlike Stat.Enable)
+	history        []ActionRecord // Actions not yet written to the actions CSV
+	historyRotated bool           // Whether the actions CSV was rotated this session
+	historyMu      sync.Mutex     // Serializes actions CSV writes
 	mu             sync.RWMutex
 }
 
@@ -202,7 +332,7 @@ func InitConfig(path string) (*Config, error) {
 				LastKilledTime: now, // Initialize to current time to prevent watchdog timeout
 				Stage:          "initializing",
 			},
-			Actions: make([]string, 0, 10),
+			Actions: make([]string, 0, statusActionCount),
 			Cooldown: Cooldown{
 				Slots: make(map[string]time.Time),
 			},
@@ -249,6 +379,11 @@ func InitConfig(path string) (*Config, error) {
 
 // createDefaultStat creates default configuration
 func (c *Config) createDefaultStat() {
+	c.Stat = defaultStat()
+}
+
+// defaultStat returns the default configuration
+func defaultStat() Stat {
 	threshold0 := 0
 	threshold50 := 50
 	threshold30 := 30
@@ -256,7 +391,9 @@ func (c *Config) createDefaultStat() {
 	cooldown3000 := 3000
 	cooldown30000 := 30000
 
-	c.Stat = Stat{
+	return Stat{
+		SchemaVersion: statSchemaVersion,
+
 		Enable:   true,
 		Restorer: true,
 		Detect:   true,
@@ -277,17 +414,54 @@ func (c *Config) createDefaultStat() {
 			ObstacleAvoidCount:    20,
 			ObstacleCoolDown:      1000,
 			EscapeHP:              10,
+			CriticalHP:            0,
+			CriticalHPRate:        0,
 			MaxTime:               300,
+			PanicMobCount:         0,
+			PanicAction:           PanicActionEscape,
+			SmartEscape:           false,
+			EscapeMaxTime:         60,
+			EscapeResumeHP:        0,
 		},
 		Settings: Settings{
-			BuffInterval:  1000,
-			DeathConfirm:  1000,
-			ShoutMessage:  "123",
-			ShoutInterval: 30,
-			WatchDogTime:  600,
-			WatchDogRetry: 3,
+			BuffInterval:      1000,
+			DeathConfirm:      1000,
+			ShoutMessage:      "123",
+			ShoutInterval:     30,
+			WatchDogTime:      600,
+			WatchDogRetry:     3,
+			NavigateTime:      60,
+			NavigateMove:      3000,
+			StuckTime:         3000,
+			StaleFrameTimeout: 2000,
+			ScreencastFormat:  ScreencastFormatPNG,
+			ScreencastQuality: 70,
+			OfflineRecovery:   OfflineRecoveryRefresh,
+			ReconnectTemplate: "reconnect.png",
+			LoginTemplate:     "login.png",
+			StartTemplate:     "start.png",
+			CharacterTemplate: "",
+			DeathTemplate:     "death.png",
+			HeatmapHalfLife:   30,
+			HeatmapRadius:     5,
+			AvoidanceTTL:      720,
+			DumpLimit:         20,
+			TargetingMode:     TargetingModeClick,
+			TargetKey:         "Tab",
+			TargetKeyRetries:  3,
+
+			ActionHistorySize:   1000,
+			ActionHistoryRotate: false,
+
+			ReturnToFarmAfterDeath: false,
+			ReturnMaxTime:          180,
+			RespawnPoint:           []float64{},
 		},
+		Regions:        DefaultDetectionRegions(),
 		StatusPath:     "status.json",
+		HeatmapPath:    "heatmap.json",
+		AvoidancePath:  "avoidance.json",
+		ActionsPath:    "actions.csv",
 		CookiesPath:    "cookie.json",
 		LogPath:        "bot.log",
 		BrowserLogPath: "browser.log",
@@ -299,14 +473,29 @@ func (c *Config) LoadConfig() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if info, err := os.Stat(c.StatPath); err == nil {
+		c.statModTime = info.ModTime()
+	}
+
 	data, err := os.ReadFile(c.StatPath)
 	if err != nil {
 		return fmt.Errorf("failed to read stat file: %w", err)
 	}
 
-	if err := json.Unmarshal(data, &c.Stat); err != nil {
+	stat, version, err := decodeStat(data)
+	if err != nil {
 		return fmt.Errorf("failed to parse stat file: %w", err)
 	}
+	c.Stat = stat
+
+	// Write the upgraded file back, so the migration runs once
+	if version < statSchemaVersion {
+		if err := c.saveStat(); err != nil {
+			log.Printf("Warning: failed to save migrated stat file: %v", err)
+		} else if info, err := os.Stat(c.StatPath); err == nil {
+			c.statModTime = info.ModTime()
+		}
+	}
 
 	// Load cookies if configured
 	if c.Stat.CookiesPath != "" {
@@ -318,6 +507,62 @@ func (c *Config) LoadConfig() error {
 	return nil
 }
 
+// statSchemaVersion is the current stat.json layout version, one more than
+// the number of statMigrations
+const statSchemaVersion = 1
+
+// statMigration upgrades stat.json by one schema version
+type statMigration struct {
+	description string
+	apply       func(stat map[string]json.RawMessage) // nil = no key changes
+}
+
+// statMigrations upgrade stat.json, index N upgrades vN to vN+1.
+// Files without schemaVersion are v0.
+var statMigrations = []statMigration{
+	{"settings missing from the file take their defaults", nil},
+}
+
+// decodeStat parses stat.json content, upgraded to statSchemaVersion, over
+// the default configuration, so settings the file does not have keep their
+// defaults. Returns the version the content was written with.
+func decodeStat(data []byte) (Stat, int, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Stat{}, 0, err
+	}
+
+	version := 0
+	if raw, ok := doc["schemaVersion"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return Stat{}, 0, fmt.Errorf("invalid schemaVersion: %w", err)
+		}
+	}
+	if version > statSchemaVersion {
+		log.Printf("Warning: stat file has schema v%d, newer than this version supports (v%d)", version, statSchemaVersion)
+	}
+	for v := version; v < statSchemaVersion; v++ {
+		log.Printf("Migrating stat file v%d -> v%d: %s", v, v+1, statMigrations[v].description)
+		if statMigrations[v].apply != nil {
+			statMigrations[v].apply(doc)
+		}
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return Stat{}, 0, err
+	}
+
+	stat := defaultStat()
+	// Slots are replaced, a slot entry must not inherit a default slot's fields
+	stat.Slots = nil
+	if err := json.Unmarshal(migrated, &stat); err != nil {
+		return Stat{}, 0, err
+	}
+	stat.SchemaVersion = statSchemaVersion
+	return stat, version, nil
+}
+
 // saveStat saves the stat configuration to file
 func (c *Config) saveStat() error {
 	data, err := json.MarshalIndent(c.Stat, "", "  ")
@@ -599,17 +844,82 @@ func (c *Config) UpdateStage(stage string) {
 	c.Status.Player.Stage = stage
 }
 
-// AddAction adds an action to the action history (max 10)
+// AddAction adds an action to the status actions (max statusActionCount) and
+// to the action history. A full history is flushed to the actions CSV.
 func (c *Config) AddAction(action string) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	c.Status.Actions = append(c.Status.Actions, action)
-	if len(c.Status.Actions) > 10 {
-		c.Status.Actions = c.Status.Actions[len(c.Status.Actions)-10:]
+	if len(c.Status.Actions) > statusActionCount {
+		c.Status.Actions = c.Status.Actions[len(c.Status.Actions)-statusActionCount:]
+	}
+
+	size := c.Stat.Settings.ActionHistorySize
+	full := false
+	if c.Stat.ActionsPath != "" && size > 0 {
+		c.history = append(c.history, ActionRecord{Time: time.Now(), Action: action})
+		full = len(c.history) >= size
+	}
+	c.mu.Unlock()
+
+	if full {
+		if err := c.FlushActionHistory(); err != nil && c.LogFile != nil {
+			// Not c.Log, it would add an action
+			log.Printf("Failed to write action history: %v", err)
+		}
 	}
 }
 
+// FlushActionHistory appends the action history to the actions CSV and clears it.
+// With ActionHistoryRotate, the CSV of the previous session is renamed on the first flush.
+func (c *Config) FlushActionHistory() error {
+	c.historyMu.Lock()
+	defer c.historyMu.Unlock()
+
+	c.mu.Lock()
+	records := c.history
+	c.history = nil
+	path := c.Stat.ActionsPath
+	rotate := c.Stat.Settings.ActionHistoryRotate && !c.historyRotated
+	c.historyRotated = true
+	c.mu.Unlock()
+
+	if len(records) == 0 || path == "" {
+		return nil
+	}
+
+	if rotate {
+		if info, err := os.Stat(path); err == nil {
+			ext := filepath.Ext(path)
+			rotated := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, ext), info.ModTime().Format("20060102-150405"), ext)
+			if err := os.Rename(path, rotated); err != nil {
+				return fmt.Errorf("failed to rotate action history: %w", err)
+			}
+		}
+	}
+
+	_, err := os.Stat(path)
+	newFile := os.IsNotExist(err)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open action history: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if newFile {
+		writer.Write([]string{"time", "action"})
+	}
+	for _, record := range records {
+		writer.Write([]string{record.Time.Format("2006-01-02 15:04:05.000"), record.Action})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write action history: %w", err)
+	}
+	return nil
+}
+
 // UpdatePlayerStats updates player HP/MP/FP
 func (c *Config) UpdatePlayerStats(hp, mp, fp int) {
 	c.mu.Lock()
@@ -660,7 +970,7 @@ func (c *Config) BrowserLog(format string, args ...interface{}) {
 	}
 }
 
-// Close closes the config (saves cookies and closes log file)
+// Close closes the config (saves cookies, writes the action history and closes log file)
 func (c *Config) Close() error {
 	if err := c.SaveCookies(); err != nil {
 		// Only log if log file is open
@@ -669,6 +979,10 @@ func (c *Config) Close() error {
 		}
 	}
 
+	if err := c.FlushActionHistory(); err != nil && c.LogFile != nil {
+		log.Printf("Failed to write action history: %v", err)
+	}
+
 	// Close browser log file
 	if c.BrowserLogFile != nil {
 		c.BrowserLogFile.Close()
@@ -681,11 +995,27 @@ func (c *Config) Close() error {
 	return nil
 }
 
-// IsEnabled checks if the bot is enabled
+// Snapshot returns a copy of the current settings, taken under the lock.
+// Readers outside Config use it instead of Stat, which ReloadConfig replaces.
+// Stat is only ever replaced as a whole, so the copy may share its slices.
+func (c *Config) Snapshot() Stat {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Stat
+}
+
+// IsEnabled checks if the bot is enabled in stat.json and was not disabled at runtime
 func (c *Config) IsEnabled() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.Stat.Enable
+	return c.Stat.Enable && !c.disabled
+}
+
+// Disable stops the bot until restart. Unlike Stat.Enable it survives a reload.
+func (c *Config) Disable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.disabled = true
 }
 
 // GetDebug checks if debug mode is enabled
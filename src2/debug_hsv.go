@@ -42,6 +42,7 @@ type DebugBrowser struct {
 	allocCtx    context.Context
 	allocCancel context.CancelFunc
 	frameChan   chan *image.RGBA
+	screencast  screencastSettings
 }
 
 // loadCookies loads cookies from cookie.json file
@@ -64,7 +65,8 @@ func loadCookies(cookiePath string) ([]Cookie, error) {
 
 func NewDebugBrowser() *DebugBrowser {
 	return &DebugBrowser{
-		frameChan: make(chan *image.RGBA, 1), // Buffer of 1 to hold latest frame
+		frameChan:  make(chan *image.RGBA, 1), // Buffer of 1 to hold latest frame
+		screencast: loadScreencastSettings("stat.json"),
 	}
 }
 
@@ -117,10 +119,11 @@ func (b *DebugBrowser) Start(cookies []Cookie) error {
 	fmt.Println("Starting screencast stream...")
 	err = chromedp.Run(b.ctx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			return page.StartScreencast().
-				WithFormat("jpeg").
-				WithQuality(70).
-				Do(ctx)
+			screencast := page.StartScreencast().WithFormat(page.ScreencastFormat(b.screencast.Format))
+			if b.screencast.Format == "jpeg" {
+				screencast = screencast.WithQuality(int64(max(0, min(b.screencast.Quality, 100))))
+			}
+			return screencast.Do(ctx)
 		}),
 	)
 	if err != nil {
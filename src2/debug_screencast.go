@@ -0,0 +1,44 @@
+//go:build ignore
+// +build ignore
+
+// Package main - debug_screencast.go
+//
+// This file holds the screencast settings shared by the debug programs
+// (debug_hsv.go, debug_status.go, debug_target.go). Run them together with it:
+// go run -tags ignore debug_status.go debug_screencast.go barfill.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// screencastSettings are the screencast settings of the bot (stat.json "settings")
+type screencastSettings struct {
+	Format  string `json:"screencastFormat"`  // "png" or "jpeg"
+	Quality int    `json:"screencastQuality"` // JPEG quality 0-100
+}
+
+// loadScreencastSettings reads the screencast settings from stat.json, so the
+// tool sees the same frames as the bot (defaults as in the bot: png, quality 70)
+func loadScreencastSettings(statPath string) screencastSettings {
+	settings := screencastSettings{Format: "png", Quality: 70}
+
+	data, err := os.ReadFile(statPath)
+	if err != nil {
+		return settings
+	}
+
+	stat := struct {
+		Settings *screencastSettings `json:"settings"`
+	}{Settings: &settings}
+	if err := json.Unmarshal(data, &stat); err != nil {
+		fmt.Printf("Warning: failed to parse %s: %v\n", statPath, err)
+	}
+	if settings.Format != "jpeg" && settings.Format != "png" {
+		fmt.Printf("Unknown screencast format %q, using png\n", settings.Format)
+		settings.Format = "png"
+	}
+	return settings
+}
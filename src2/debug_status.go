@@ -43,6 +43,7 @@ type DebugBrowser struct {
 	allocCtx    context.Context
 	allocCancel context.CancelFunc
 	frameChan   chan *image.RGBA
+	screencast  screencastSettings
 }
 
 // loadCookies loads cookies from cookie.json file
@@ -65,7 +66,8 @@ func loadCookies(cookiePath string) ([]Cookie, error) {
 
 func NewDebugBrowser() *DebugBrowser {
 	return &DebugBrowser{
-		frameChan: make(chan *image.RGBA, 1), // Buffer of 1 to hold latest frame
+		frameChan:  make(chan *image.RGBA, 1), // Buffer of 1 to hold latest frame
+		screencast: loadScreencastSettings("stat.json"),
 	}
 }
 
@@ -118,10 +120,11 @@ func (b *DebugBrowser) Start(cookies []Cookie) error {
 	fmt.Println("Starting screencast stream...")
 	err = chromedp.Run(b.ctx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			return page.StartScreencast().
-				WithFormat("jpeg").
-				WithQuality(70).
-				Do(ctx)
+			screencast := page.StartScreencast().WithFormat(page.ScreencastFormat(b.screencast.Format))
+			if b.screencast.Format == "jpeg" {
+				screencast = screencast.WithQuality(int64(max(0, min(b.screencast.Quality, 100))))
+			}
+			return screencast.Do(ctx)
 		}),
 	)
 	if err != nil {
@@ -461,7 +464,8 @@ type StatusBarInfo struct {
 	Rect       image.Rectangle
 	FillWidth  int
 	Percentage float64
-	Type       string // "HP", "MP", or "FP"
+	Confidence float64 // Fraction of rows filled at the fill edge
+	Type       string  // "HP", "MP", or "FP"
 }
 
 // HSVRange holds HSV color range parameters for a status bar
@@ -842,33 +846,21 @@ func detectStatusBars2(mat gocv.Mat, windowMorph *gocv.Window, windowFrame *gocv
 			defer masks[i].Close()
 			gocv.InRangeWithScalar(barROI, lower, upper, masks[i])
 
-			// Find the rightmost white pixel to determine fill width
-			fillWidth := 0
-			for x := barRect.Dx() - 1; x >= 0; x-- {
-				hasWhite := false
-				for y := 0; y < barRect.Dy(); y++ {
-					if masks[i].GetUCharAt(y, x) > 0 {
-						hasWhite = true
-						break
-					}
-				}
-				if hasWhite {
-					fillWidth = x + 1
-					break
-				}
-			}
-
+			// Find the rightmost white column to determine fill width
+			fill := measureBarFill(*masks[i])
+			fillWidth := fill.Width
 			percentage := float64(fillWidth) / float64(barRect.Dx()) * 100
 
 			statusBars = append(statusBars, StatusBarInfo{
 				Rect:       barRect,
 				FillWidth:  fillWidth,
 				Percentage: percentage,
+				Confidence: fill.Confidence,
 				Type:       barType,
 			})
 
 			if printProgress {
-				fmt.Printf("%s: width=%d fill=%d (%.1f%%)\n", barType, barRect.Dx(), fillWidth, percentage)
+				fmt.Printf("%s: width=%d fill=%d (%.1f%%, confidence %.2f)\n", barType, barRect.Dx(), fillWidth, percentage, fill.Confidence)
 			}
 		}
 	}
@@ -1337,20 +1329,14 @@ func detectStatusBars3(mat gocv.Mat, status *detectMyStatus) gocv.Mat {
 			defer mask.Close()
 			gocv.InRangeWithScalar(barROI, lower, upper, &mask)
 
-			// Find the rightmost white pixel using Reduce (sum each column)
-			fillWidth := 0
-			// Reduce: sum each column to a single row
-			colSums := gocv.NewMat()
-			defer colSums.Close()
-			gocv.Reduce(mask, &colSums, 0, gocv.ReduceSum, gocv.MatTypeCV32F)
-
-			// Find rightmost non-zero column
-			for x := colSums.Cols() - 1; x >= 0; x-- {
-				if colSums.GetFloatAt(0, x) > 0 {
-					fillWidth = x + 1
-					break
-				}
+			// Find the rightmost filled column, keeping the last value on a
+			// ragged edge (usually stray pixels rather than the bar)
+			fill := measureBarFill(mask)
+			if fill.Width > 0 && !fill.Reliable() {
+				widths[i] = barStatus.Width
+				continue
 			}
+			fillWidth := fill.Width
 
 			widths[i] = fillWidth
 
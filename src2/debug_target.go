@@ -43,6 +43,7 @@ type DebugBrowser struct {
 	allocCtx    context.Context
 	allocCancel context.CancelFunc
 	frameChan   chan *image.RGBA
+	screencast  screencastSettings
 }
 
 // loadCookies loads cookies from cookie.json file
@@ -65,7 +66,8 @@ func loadCookies(cookiePath string) ([]Cookie, error) {
 
 func NewDebugBrowser() *DebugBrowser {
 	return &DebugBrowser{
-		frameChan: make(chan *image.RGBA, 1), // Buffer of 1 to hold latest frame
+		frameChan:  make(chan *image.RGBA, 1), // Buffer of 1 to hold latest frame
+		screencast: loadScreencastSettings("stat.json"),
 	}
 }
 
@@ -118,10 +120,11 @@ func (b *DebugBrowser) Start(cookies []Cookie) error {
 	fmt.Println("Starting screencast stream...")
 	err = chromedp.Run(b.ctx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			return page.StartScreencast().
-				WithFormat("jpeg").
-				WithQuality(70).
-				Do(ctx)
+			screencast := page.StartScreencast().WithFormat(page.ScreencastFormat(b.screencast.Format))
+			if b.screencast.Format == "jpeg" {
+				screencast = screencast.WithQuality(int64(max(0, min(b.screencast.Quality, 100))))
+			}
+			return screencast.Do(ctx)
 		}),
 	)
 	if err != nil {
@@ -380,7 +383,8 @@ type TargetBarInfo struct {
 	Rect       image.Rectangle
 	FillWidth  int
 	Percentage float64
-	Type       string // "HP" or "MP"
+	Confidence float64 // Fraction of rows filled at the fill edge
+	Type       string  // "HP" or "MP"
 }
 
 // ROIParams4 defines the region of interest for target detection
@@ -998,30 +1002,21 @@ func detectStatusBars4(mat gocv.Mat, window1 *gocv.Window, window2 *gocv.Window,
 				defer mask.Close()
 				gocv.InRangeWithScalar(barROI, lower, upper, &mask)
 
-				// Find the rightmost white pixel
-				fillWidth := 0
-				colSums := gocv.NewMat()
-				defer colSums.Close()
-				gocv.Reduce(mask, &colSums, 0, gocv.ReduceSum, gocv.MatTypeCV32F)
-
-				for x := colSums.Cols() - 1; x >= 0; x-- {
-					if colSums.GetFloatAt(0, x) > 0 {
-						fillWidth = x + 1
-						break
-					}
-				}
-
+				// Find the rightmost white column
+				fill := measureBarFill(mask)
+				fillWidth := fill.Width
 				percentage := float64(fillWidth) / float64(barRect.Dx()) * 100
 
 				targetBars = append(targetBars, TargetBarInfo{
 					Rect:       barRect,
 					FillWidth:  fillWidth,
 					Percentage: percentage,
+					Confidence: fill.Confidence,
 					Type:       barType,
 				})
 
 				if printProgress {
-					fmt.Printf("%s: width=%d fill=%d (%.1f%%)\n", barType, barRect.Dx(), fillWidth, percentage)
+					fmt.Printf("%s: width=%d fill=%d (%.1f%%, confidence %.2f)\n", barType, barRect.Dx(), fillWidth, percentage, fill.Confidence)
 				}
 			}
 		}
@@ -7,16 +7,24 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
+	"os"
 
 	"gocv.io/x/gocv"
 )
 
-// ROIArea defines the region of interest for detection
+// ROIArea defines the region of interest for detection.
+// Negative values are relative to the right/bottom edge of the frame.
 type ROIArea struct {
-	MinX int
-	MaxX int
-	MinY int
-	MaxY int
+	MinX int `json:"minX"`
+	MaxX int `json:"maxX"`
+	MinY int `json:"minY"`
+	MaxY int `json:"maxY"`
+}
+
+// IsZero reports whether the area is unset
+func (r ROIArea) IsZero() bool {
+	return r == ROIArea{}
 }
 
 // BarKind represents the type of status bar
@@ -105,6 +113,7 @@ type ClientDetect struct {
 	MyStats StatsBar  // Player stats
 	Target  StatsBar  // Target stats
 	Mobs    Mobs      // Mobs detection
+	Minimap ROIArea   // Minimap region used by DetectDirection
 	mat     *gocv.Mat // Current frame image in Mat format (pointer, nil if not initialized)
 	Config  *Config   // Config reference for logging
 }
@@ -116,8 +125,13 @@ func NewClientDetect(cfg *Config) *ClientDetect {
 		Config: cfg,
 	}
 
+	// Detection regions from stat.json, unset regions keep the defaults
+	regions := DefaultDetectionRegions()
+	regions.Merge(cfg.Snapshot().Regions)
+	cd.Minimap = regions.Minimap
+
 	// Initialize MyStats
-	cd.MyStats.ROI = ROIArea{MinX: 0, MinY: 0, MaxX: 500, MaxY: 350}
+	cd.MyStats.ROI = regions.StatusBar
 	cd.MyStats.HP = BarInfo{
 		BarKind: BarKindHP,
 		MinH:    160, MaxH: 180,
@@ -148,7 +162,7 @@ func NewClientDetect(cfg *Config) *ClientDetect {
 	}
 
 	// Initialize Target
-	cd.Target.ROI = ROIArea{MinX: 400, MinY: 200, MaxX: -400, MaxY: 200}
+	cd.Target.ROI = regions.TargetBar
 	cd.Target.HP = BarInfo{
 		BarKind: BarKindTargetHP,
 		MinH:    340, MaxH: 350,
@@ -173,7 +187,7 @@ func NewClientDetect(cfg *Config) *ClientDetect {
 	}
 
 	// Initialize Mobs
-	cd.Mobs.ROI = ROIArea{MinX: 0, MinY: 0, MaxX: -1, MaxY: -100} // Full screen except bottom 100px
+	cd.Mobs.ROI = regions.MobArea
 	cd.Mobs.AggressiveInfo = MobsInfo{
 		MinH: 0, MaxH: 10,
 		MinS: 200, MaxS: 255,
@@ -202,6 +216,11 @@ func NewClientDetect(cfg *Config) *ClientDetect {
 	cd.Mobs.PassiveMobs = make([]MobsPosition, 0)
 	cd.Mobs.VioletMobs = make([]MobsPosition, 0)
 
+	// Without the respawn dialog template a misread HP bar counts as death
+	if path := cfg.Snapshot().Settings.DeathTemplate; path != "" && !templateExists(path) {
+		cfg.Log("Death template %s not found, death is detected from HP alone (capture the respawn dialog to %s)", path, path)
+	}
+
 	return cd
 }
 
@@ -222,6 +241,43 @@ func (cd *ClientDetect) UpdateImage(img *image.RGBA) error {
 	return nil
 }
 
+// ScreenSize returns the size of the current frame (zero if no frame)
+func (cd *ClientDetect) ScreenSize() image.Point {
+	if cd.mat == nil || cd.mat.Empty() {
+		return image.Point{}
+	}
+	return image.Pt(cd.mat.Cols(), cd.mat.Rows())
+}
+
+// resolveROI converts an ROI with edge-relative values to a rectangle clamped to
+// the frame (uses internal mat). Returns false if nothing of the ROI is inside the frame.
+func (cd *ClientDetect) resolveROI(roi ROIArea, name string) (image.Rectangle, bool) {
+	if cd.mat == nil || cd.mat.Empty() {
+		return image.Rectangle{}, false
+	}
+
+	cols, rows := cd.mat.Cols(), cd.mat.Rows()
+	if roi.MinX < 0 {
+		roi.MinX += cols
+	}
+	if roi.MaxX < 0 {
+		roi.MaxX += cols
+	}
+	if roi.MinY < 0 {
+		roi.MinY += rows
+	}
+	if roi.MaxY < 0 {
+		roi.MaxY += rows
+	}
+
+	rect := image.Rect(roi.MinX, roi.MinY, roi.MaxX, roi.MaxY)
+	clamped := rect.Intersect(image.Rect(0, 0, cols, rows))
+	if clamped != rect && cd.Debug && cd.Config != nil {
+		cd.Config.Log("[ROI] %s region %v clamped to frame %dx%d: %v", name, rect, cols, rows, clamped)
+	}
+	return clamped, !clamped.Empty()
+}
+
 // Close releases the mat resource
 func (cd *ClientDetect) Close() {
 	if cd.mat != nil {
@@ -236,30 +292,15 @@ func (cd *ClientDetect) updateStateDetect(barInfo *BarInfo, roi ROIArea, filter
 		return
 	}
 
-	// Adjust ROI for negative values (relative to image size)
-	actualROI := roi
-	if actualROI.MinX < 0 {
-		actualROI.MinX = cd.mat.Cols() + actualROI.MinX
-	}
-	if actualROI.MaxX < 0 {
-		actualROI.MaxX = cd.mat.Cols() + actualROI.MaxX
-	}
-	if actualROI.MinY < 0 {
-		actualROI.MinY = cd.mat.Rows() + actualROI.MinY
-	}
-	if actualROI.MaxY < 0 {
-		actualROI.MaxY = cd.mat.Rows() + actualROI.MaxY
-	}
-
-	// Ensure ROI is within image bounds
-	if actualROI.MinX < 0 || actualROI.MinY < 0 ||
-		actualROI.MaxX > cd.mat.Cols() || actualROI.MaxY > cd.mat.Rows() ||
-		actualROI.MinX >= actualROI.MaxX || actualROI.MinY >= actualROI.MaxY {
+	// Resolve ROI against the frame size
+	roiRect, ok := cd.resolveROI(roi, debugName)
+	if !ok {
 		return
 	}
+	actualROI := ROIArea{MinX: roiRect.Min.X, MaxX: roiRect.Max.X, MinY: roiRect.Min.Y, MaxY: roiRect.Max.Y}
 
 	// Extract ROI
-	roiMat := cd.mat.Region(image.Rect(actualROI.MinX, actualROI.MinY, actualROI.MaxX, actualROI.MaxY))
+	roiMat := cd.mat.Region(roiRect)
 	defer roiMat.Close()
 
 	// Convert to HSV
@@ -307,6 +348,7 @@ func (cd *ClientDetect) updateStateDetect(barInfo *BarInfo, roi ROIArea, filter
 
 	// Find the largest valid contour
 	maxWidth := 0
+	var barRect image.Rectangle
 	for i := 0; i < contours.Size(); i++ {
 		contour := contours.At(i)
 		rect := gocv.BoundingRect(contour)
@@ -319,10 +361,18 @@ func (cd *ClientDetect) updateStateDetect(barInfo *BarInfo, roi ROIArea, filter
 			height >= filter.MinHeight && height <= filter.MaxHeight {
 			if width > maxWidth {
 				maxWidth = width
+				barRect = rect
 			}
 		}
 	}
 
+	// Debug: save the matched pixels and the detected bar bounds
+	if debug && debugName != "" {
+		if err := dumpBarMask(roiMat, mask, barRect, debugName); err != nil && cd.Config != nil {
+			cd.Config.Log("[Dump] %s bar mask: %v", debugName, err)
+		}
+	}
+
 	// Update barInfo
 	prevWidth := barInfo.Width
 	barInfo.Width = maxWidth
@@ -433,30 +483,15 @@ func (cd *ClientDetect) updateMobsDetect(mobsList *[]MobsPosition, mobsInfo *Mob
 	// Clear mobs list
 	*mobsList = (*mobsList)[:0]
 
-	// Adjust ROI for negative values (relative to image size)
-	actualROI := roi
-	if actualROI.MinX < 0 {
-		actualROI.MinX = cd.mat.Cols() + actualROI.MinX
-	}
-	if actualROI.MaxX < 0 {
-		actualROI.MaxX = cd.mat.Cols() + actualROI.MaxX
-	}
-	if actualROI.MinY < 0 {
-		actualROI.MinY = cd.mat.Rows() + actualROI.MinY
-	}
-	if actualROI.MaxY < 0 {
-		actualROI.MaxY = cd.mat.Rows() + actualROI.MaxY
-	}
-
-	// Ensure ROI is within image bounds
-	if actualROI.MinX < 0 || actualROI.MinY < 0 ||
-		actualROI.MaxX > cd.mat.Cols() || actualROI.MaxY > cd.mat.Rows() ||
-		actualROI.MinX >= actualROI.MaxX || actualROI.MinY >= actualROI.MaxY {
+	// Resolve ROI against the frame size
+	roiRect, ok := cd.resolveROI(roi, debugName)
+	if !ok {
 		return
 	}
+	actualROI := ROIArea{MinX: roiRect.Min.X, MaxX: roiRect.Max.X, MinY: roiRect.Min.Y, MaxY: roiRect.Max.Y}
 
 	// Extract ROI
-	roiMat := cd.mat.Region(image.Rect(actualROI.MinX, actualROI.MinY, actualROI.MaxX, actualROI.MaxY))
+	roiMat := cd.mat.Region(roiRect)
 	defer roiMat.Close()
 
 	// Convert to HSV
@@ -574,6 +609,7 @@ func (cd *ClientDetect) updateMobs(debug bool) {
 // UpdateMyStats updates player stats detection
 func (cd *ClientDetect) UpdateMyStats() {
 	cd.updateState(&cd.MyStats, cd.Debug, "My")
+	cd.updateDeath()
 }
 
 // UpdateTargetStats updates target stats detection
@@ -586,9 +622,335 @@ func (cd *ClientDetect) UpdateMobs() {
 	cd.updateMobs(cd.Debug)
 }
 
-// UpdateClientDetect updates all client detection data (uses internal mat)
-func (cd *ClientDetect) UpdateClientDetect() {
-	cd.updateState(&cd.MyStats, cd.Debug, "My")
-	cd.updateState(&cd.Target, cd.Debug, "Target")
-	cd.updateMobs(cd.Debug)
+// DirectionInfo represents the minimap analysis result
+type DirectionInfo struct {
+	CurrentAngle float64       // Player arrow direction in degrees (-180 to 180, screen coordinates)
+	BestAngle    float64       // Direction with the highest monster density (-180 to 180)
+	Found        bool          // Whether any monster was found on the minimap
+	Monsters     []image.Point // Monster offsets from the player in minimap pixels
+}
+
+// DetectDirection analyzes the minimap to find the best direction for monster density (uses internal mat)
+func (cd *ClientDetect) DetectDirection() DirectionInfo {
+	if cd.mat == nil || cd.mat.Empty() {
+		return DirectionInfo{Found: false}
+	}
+
+	// Minimap is in the upper right corner by default
+	rect, ok := cd.resolveROI(cd.Minimap, "Minimap")
+	if !ok {
+		return DirectionInfo{Found: false}
+	}
+
+	roi := cd.mat.Region(rect)
+	defer roi.Close()
+
+	hsv := gocv.NewMat()
+	defer hsv.Close()
+	gocv.CvtColor(roi, &hsv, gocv.ColorBGRToHSV)
+
+	// Orange dots are monsters
+	maskOrange := gocv.NewMat()
+	defer maskOrange.Close()
+	gocv.InRangeWithScalar(hsv, gocv.NewScalar(5, 100, 150, 0), gocv.NewScalar(25, 255, 255, 0), &maskOrange)
+
+	// White arrow is the player direction indicator
+	maskWhite := gocv.NewMat()
+	defer maskWhite.Close()
+	gocv.InRangeWithScalar(hsv, gocv.NewScalar(0, 0, 200, 0), gocv.NewScalar(180, 30, 255, 0), &maskWhite)
+
+	// Player is at the center of the minimap
+	centerX := roi.Cols() / 2
+	centerY := roi.Rows() / 2
+	currentAngle := arrowDirection(maskWhite, centerX, centerY)
+
+	// Weight 10-degree sectors by monster count, closer monsters weigh more
+	sectors := 36
+	sectorWeights := make([]float64, sectors)
+	var monsters []image.Point
+
+	contours := gocv.FindContours(maskOrange, gocv.RetrievalExternal, gocv.ChainApproxSimple)
+	defer contours.Close()
+
+	for i := 0; i < contours.Size(); i++ {
+		rect := gocv.BoundingRect(contours.At(i))
+		dx := float64(rect.Min.X + rect.Dx()/2 - centerX)
+		dy := float64(rect.Min.Y + rect.Dy()/2 - centerY)
+		distance := math.Sqrt(dx*dx + dy*dy)
+		if distance < 5 { // Skip player area
+			continue
+		}
+		monsters = append(monsters, image.Pt(int(dx), int(dy)))
+
+		degrees := math.Atan2(dy, dx) * 180 / math.Pi
+		if degrees < 0 {
+			degrees += 360
+		}
+		sector := int(degrees / (360.0 / float64(sectors)))
+		if sector >= sectors {
+			sector = sectors - 1
+		}
+		sectorWeights[sector] += 1.0 / (1.0 + distance/20.0)
+	}
+
+	maxWeight := 0.0
+	bestSector := 0
+	for i, weight := range sectorWeights {
+		if weight > maxWeight {
+			maxWeight = weight
+			bestSector = i
+		}
+	}
+
+	if maxWeight == 0 {
+		// The arrow direction is still valid without monsters
+		return DirectionInfo{CurrentAngle: normalizeAngle(currentAngle), Found: false}
+	}
+
+	// Center of the best sector
+	bestAngle := float64(bestSector)*(360.0/float64(sectors)) + (180.0 / float64(sectors))
+
+	return DirectionInfo{
+		CurrentAngle: normalizeAngle(currentAngle),
+		BestAngle:    normalizeAngle(bestAngle),
+		Found:        true,
+		Monsters:     monsters,
+	}
+}
+
+// escapeSectors is the number of minimap sectors compared when looking for open space
+const escapeSectors = 8
+
+// OpenAngle returns the center of the minimap sector with the fewest monsters
+// (closer monsters weigh more, half of each neighboring sector counts too so
+// the path does not graze a crowd). Without monsters it is CurrentAngle.
+func (d DirectionInfo) OpenAngle() float64 {
+	if len(d.Monsters) == 0 {
+		return d.CurrentAngle
+	}
+
+	sectorSize := 360.0 / escapeSectors
+	weights := make([]float64, escapeSectors)
+	for _, monster := range d.Monsters {
+		dx, dy := float64(monster.X), float64(monster.Y)
+		degrees := math.Atan2(dy, dx) * 180 / math.Pi
+		if degrees < 0 {
+			degrees += 360
+		}
+		sector := min(int(degrees/sectorSize), escapeSectors-1)
+		weights[sector] += 1.0 / (1.0 + math.Sqrt(dx*dx+dy*dy)/20.0)
+	}
+
+	best := 0
+	bestWeight := math.Inf(1)
+	for i := range weights {
+		weight := weights[i] + 0.5*(weights[(i+escapeSectors-1)%escapeSectors]+weights[(i+1)%escapeSectors])
+		if weight < bestWeight {
+			best = i
+			bestWeight = weight
+		}
+	}
+	return normalizeAngle(float64(best)*sectorSize + sectorSize/2)
+}
+
+// Player arrow detection on the minimap mask
+const (
+	arrowMaxCenterDist = 15.0 // Max distance between the arrow and the minimap center in pixels
+	arrowMinArea       = 6.0  // Smallest contour area accepted as the arrow
+	arrowApproxEpsilon = 0.1  // ApproxPolyDP epsilon as a fraction of the contour perimeter
+)
+
+// arrowDirection calculates the player arrow direction in degrees. It points from
+// the centroid of the triangular contour nearest the center to its tip; without
+// such a contour it falls back to the white pixel centroid, which other white
+// minimap marks (labels, the north marker) pull off course.
+func arrowDirection(mask gocv.Mat, centerX, centerY int) float64 {
+	if angle, ok := arrowTriangleDirection(mask, centerX, centerY); ok {
+		return angle
+	}
+	return arrowCentroidDirection(mask, centerX, centerY)
+}
+
+// arrowTriangleDirection finds the triangular contour closest to the center
+// and returns the angle from its centroid to its tip
+func arrowTriangleDirection(mask gocv.Mat, centerX, centerY int) (float64, bool) {
+	contours := gocv.FindContours(mask, gocv.RetrievalExternal, gocv.ChainApproxSimple)
+	defer contours.Close()
+
+	found := false
+	bestDist := arrowMaxCenterDist
+	bestAngle := 0.0
+
+	for i := 0; i < contours.Size(); i++ {
+		contour := contours.At(i)
+		if gocv.ContourArea(contour) < arrowMinArea {
+			continue
+		}
+
+		epsilon := arrowApproxEpsilon * gocv.ArcLength(contour, true)
+		approx := gocv.ApproxPolyDP(contour, epsilon, true)
+		points := approx.ToPoints()
+		approx.Close()
+		if len(points) != 3 {
+			continue
+		}
+
+		// Centroid of the triangle
+		cx := float64(points[0].X+points[1].X+points[2].X) / 3
+		cy := float64(points[0].Y+points[1].Y+points[2].Y) / 3
+
+		dist := math.Hypot(cx-float64(centerX), cy-float64(centerY))
+		if dist > bestDist {
+			continue
+		}
+
+		// The tip is the vertex farthest from the centroid (the arrow is a narrow isosceles triangle)
+		tip := points[0]
+		tipDist := 0.0
+		for _, p := range points {
+			if d := math.Hypot(float64(p.X)-cx, float64(p.Y)-cy); d > tipDist {
+				tipDist = d
+				tip = p
+			}
+		}
+
+		found = true
+		bestDist = dist
+		bestAngle = math.Atan2(float64(tip.Y)-cy, float64(tip.X)-cx) * 180 / math.Pi
+	}
+
+	return bestAngle, found
+}
+
+// arrowCentroidDirection estimates the arrow direction from the white pixel centroid
+func arrowCentroidDirection(mask gocv.Mat, centerX, centerY int) float64 {
+	sumX, sumY, count := 0.0, 0.0, 0
+	for y := 0; y < mask.Rows(); y++ {
+		for x := 0; x < mask.Cols(); x++ {
+			if mask.GetUCharAt(y, x) > 0 {
+				sumX += float64(x)
+				sumY += float64(y)
+				count++
+			}
+		}
+	}
+
+	if count == 0 {
+		return 0
+	}
+
+	dx := sumX/float64(count) - float64(centerX)
+	dy := sumY/float64(count) - float64(centerY)
+	return math.Atan2(dy, dx) * 180 / math.Pi
+}
+
+// normalizeAngle normalizes an angle to the -180 to 180 range
+func normalizeAngle(angle float64) float64 {
+	for angle > 180 {
+		angle -= 360
+	}
+	for angle <= -180 {
+		angle += 360
+	}
+	return angle
+}
+
+// MinimapSnapshot returns a grayscale float copy of the minimap for MinimapShift.
+// The caller must close the returned Mat.
+func (cd *ClientDetect) MinimapSnapshot() (gocv.Mat, bool) {
+	if cd.mat == nil || cd.mat.Empty() {
+		return gocv.Mat{}, false
+	}
+
+	rect, ok := cd.resolveROI(cd.Minimap, "Minimap")
+	if !ok {
+		return gocv.Mat{}, false
+	}
+
+	roi := cd.mat.Region(rect)
+	defer roi.Close()
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(roi, &gray, gocv.ColorBGRToGray)
+
+	snapshot := gocv.NewMat()
+	gray.ConvertTo(&snapshot, gocv.MatTypeCV32F)
+	return snapshot, true
+}
+
+// MinimapShift returns how far (minimap pixels) the minimap content moved between two snapshots.
+// The player arrow stays at the center, so the map scrolling under it is the player moving.
+func MinimapShift(prev, cur gocv.Mat) (float64, bool) {
+	if prev.Empty() || cur.Empty() || prev.Rows() != cur.Rows() || prev.Cols() != cur.Cols() {
+		return 0, false
+	}
+
+	window := gocv.NewMat()
+	defer window.Close()
+	shift, _ := gocv.PhaseCorrelate(prev, cur, window)
+	return math.Hypot(float64(shift.X), float64(shift.Y)), true
+}
+
+// FindTemplate locates a template image in the current frame (uses internal mat).
+// Returns the center of the best match if its normalized correlation reaches threshold.
+func (cd *ClientDetect) FindTemplate(path string, threshold float32) (image.Point, bool) {
+	if cd.mat == nil || cd.mat.Empty() {
+		return image.Point{}, false
+	}
+
+	tmpl := gocv.IMRead(path, gocv.IMReadColor)
+	if tmpl.Empty() {
+		cd.Config.Log("Failed to load template %s", path)
+		return image.Point{}, false
+	}
+	defer tmpl.Close()
+
+	if tmpl.Cols() > cd.mat.Cols() || tmpl.Rows() > cd.mat.Rows() {
+		return image.Point{}, false
+	}
+
+	result := gocv.NewMat()
+	defer result.Close()
+	mask := gocv.NewMat()
+	defer mask.Close()
+	gocv.MatchTemplate(*cd.mat, tmpl, &result, gocv.TmCcoeffNormed, mask)
+
+	_, maxVal, _, maxLoc := gocv.MinMaxLoc(result)
+	if maxVal < threshold {
+		return image.Point{}, false
+	}
+
+	return image.Pt(maxLoc.X+tmpl.Cols()/2, maxLoc.Y+tmpl.Rows()/2), true
+}
+
+// templateExists reports whether a template image is configured and on disk
+func templateExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// updateDeath confirms a zero HP reading with the respawn dialog (uses internal mat).
+// HP also reads 0 when the bar detection fails, which would otherwise send the bot
+// into StageDead and spam death confirms, so the player is only dead while the
+// respawn dialog is visible. Without a death template HP alone decides.
+func (cd *ClientDetect) updateDeath() {
+	if !cd.MyStats.Open || cd.MyStats.Alive {
+		return
+	}
+
+	path := cd.Config.Snapshot().Settings.DeathTemplate
+	if !templateExists(path) {
+		return
+	}
+
+	if _, found := cd.FindTemplate(path, 0.8); !found {
+		cd.MyStats.Alive = true
+		if cd.Debug {
+			cd.Config.Log("HP reads 0 but no respawn dialog, assuming misdetection")
+		}
+	}
 }
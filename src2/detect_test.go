@@ -0,0 +1,156 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// loadFrame reads a captured game frame as RGBA
+func loadFrame(t *testing.T, path string) *image.RGBA {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", path, err)
+	}
+	frame := image.NewRGBA(img.Bounds())
+	draw.Draw(frame, frame.Bounds(), img, img.Bounds().Min, draw.Src)
+	return frame
+}
+
+// drawRespawnDialog draws a respawn dialog stand-in at the center of frame (a
+// dark panel with a gold frame, a title bar and two buttons) and returns its bounds
+func drawRespawnDialog(frame *image.RGBA) image.Rectangle {
+	center := image.Pt(frame.Bounds().Dx()/2, frame.Bounds().Dy()/2)
+	dialog := image.Rect(center.X-180, center.Y-90, center.X+180, center.Y+90)
+
+	fill := func(rect image.Rectangle, c color.RGBA) {
+		draw.Draw(frame, rect, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+	fill(dialog, color.RGBA{200, 160, 60, 255})
+	fill(dialog.Inset(4), color.RGBA{30, 25, 20, 255})
+	fill(image.Rect(dialog.Min.X+4, dialog.Min.Y+4, dialog.Max.X-4, dialog.Min.Y+30), color.RGBA{90, 60, 30, 255})
+	fill(image.Rect(dialog.Min.X+40, dialog.Max.Y-50, dialog.Min.X+160, dialog.Max.Y-20), color.RGBA{170, 140, 90, 255})
+	fill(image.Rect(dialog.Max.X-160, dialog.Max.Y-50, dialog.Max.X-40, dialog.Max.Y-20), color.RGBA{170, 140, 90, 255})
+	for y := dialog.Min.Y + 50; y < dialog.Min.Y+110; y += 12 {
+		fill(image.Rect(dialog.Min.X+30, y, dialog.Max.X-30, y+4), color.RGBA{220, 220, 210, 255})
+	}
+	return dialog
+}
+
+// writeTemplate saves the rect of frame as a template image in dir
+func writeTemplate(t *testing.T, frame *image.RGBA, rect image.Rectangle, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "death.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create template: %v", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, frame.SubImage(rect)); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	return path
+}
+
+func TestUpdateDeath(t *testing.T) {
+	alive := loadFrame(t, "../opencv/WechatIMG1230.png")
+
+	dead := image.NewRGBA(alive.Bounds())
+	draw.Draw(dead, dead.Bounds(), alive, alive.Bounds().Min, draw.Src)
+	dialog := drawRespawnDialog(dead)
+	template := writeTemplate(t, dead, dialog, t.TempDir())
+
+	tests := []struct {
+		name      string
+		frame     *image.RGBA
+		template  string
+		wantAlive bool
+	}{
+		{"respawn dialog visible", dead, template, false},
+		{"HP misread without dialog", alive, template, true},
+		{"no death template", alive, filepath.Join(t.TempDir(), "missing.png"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Stat: defaultStat()}
+			cfg.Stat.ActionsPath = ""
+			cfg.Stat.Settings.DeathTemplate = tt.template
+
+			cd := NewClientDetect(cfg)
+			defer cd.Close()
+			if err := cd.UpdateImage(tt.frame); err != nil {
+				t.Fatalf("UpdateImage failed: %v", err)
+			}
+
+			// HP reads 0
+			cd.MyStats.Open = true
+			cd.MyStats.Alive = false
+			cd.updateDeath()
+
+			if cd.MyStats.Alive != tt.wantAlive {
+				t.Errorf("Alive = %v, want %v", cd.MyStats.Alive, tt.wantAlive)
+			}
+		})
+	}
+}
+
+// minimapMask returns the white mask of a 160x160 minimap with the player arrow
+// at the center pointing at angle (degrees, screen coordinates), plus the north
+// marker and a label, which are white too
+func minimapMask(angle float64) gocv.Mat {
+	mask := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 0, 0, 0), 160, 160, gocv.MatTypeCV8U)
+	white := color.RGBA{255, 255, 255, 0}
+
+	rad := angle * math.Pi / 180
+	ux, uy := math.Cos(rad), math.Sin(rad)
+	point := func(ahead, side float64) image.Point {
+		return image.Pt(int(math.Round(80+ahead*ux-side*uy)), int(math.Round(80+ahead*uy+side*ux)))
+	}
+	arrow := gocv.NewPointsVectorFromPoints([][]image.Point{{point(12, 0), point(-6, -6), point(-6, 6)}})
+	defer arrow.Close()
+	gocv.FillPoly(&mask, arrow, white)
+
+	gocv.Rectangle(&mask, image.Rect(74, 2, 86, 14), white, -1)      // North marker
+	gocv.Rectangle(&mask, image.Rect(110, 120, 150, 128), white, -1) // Label
+	return mask
+}
+
+func TestArrowDirection(t *testing.T) {
+	for _, want := range []float64{0, 45, 90, -135, 180} {
+		mask := minimapMask(want)
+		got := arrowDirection(mask, 80, 80)
+		mask.Close()
+
+		if diff := math.Abs(normalizeAngle(got - want)); diff > 10 {
+			t.Errorf("arrow at %.0f: arrowDirection() = %.1f", want, got)
+		}
+	}
+}
+
+func TestArrowDirectionWithoutArrow(t *testing.T) {
+	// No triangle near the center: the white pixel centroid is used
+	mask := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 0, 0, 0), 160, 160, gocv.MatTypeCV8U)
+	defer mask.Close()
+	gocv.Rectangle(&mask, image.Rect(120, 76, 130, 84), color.RGBA{255, 255, 255, 0}, -1)
+
+	if got := arrowDirection(mask, 80, 80); math.Abs(got) > 1 {
+		t.Errorf("arrowDirection() = %.1f, want 0", got)
+	}
+}
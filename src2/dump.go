@@ -0,0 +1,168 @@
+// Package main - dump.go
+//
+// This file saves detection dumps (frame + detected bars) for debugging recognition failures,
+// and in debug mode the matched pixels of every status bar (dumps/bars/<bar>.png).
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// dumpDir is the directory detection dumps are written to
+const dumpDir = "dumps"
+
+// barDumpDir holds the latest mask of each status bar, overwritten every frame in debug mode
+var barDumpDir = filepath.Join(dumpDir, "bars")
+
+// barDumpScale enlarges the few pixels high bar masks so single pixels are visible
+const barDumpScale = 8
+
+// dumpStats is the JSON representation of a StatsBar in a dump
+type dumpStats struct {
+	Open  bool    `json:"open"`
+	Alive bool    `json:"alive"`
+	NPC   bool    `json:"npc"`
+	ROI   ROIArea `json:"roi"`
+	HP    BarInfo `json:"hp"`
+	MP    BarInfo `json:"mp"`
+	FP    BarInfo `json:"fp"`
+}
+
+// dumpInfo is the JSON written next to the dumped frame
+type dumpInfo struct {
+	Reason  string    `json:"reason"`
+	Time    time.Time `json:"time"`
+	Width   int       `json:"width"`
+	Height  int       `json:"height"`
+	MyStats dumpStats `json:"myStats"`
+	Target  dumpStats `json:"target"`
+}
+
+// newDumpStats copies the detection result of a StatsBar
+func newDumpStats(stats *StatsBar) dumpStats {
+	return dumpStats{
+		Open:  stats.Open,
+		Alive: stats.Alive,
+		NPC:   stats.NPC,
+		ROI:   stats.ROI,
+		HP:    stats.HP,
+		MP:    stats.MP,
+		FP:    stats.FP,
+	}
+}
+
+// Dump saves the current frame and the detected bars to dumpDir (uses internal mat).
+// Only the newest limit dumps are kept (0 = unlimited).
+func (cd *ClientDetect) Dump(reason string, limit int) error {
+	if cd.mat == nil || cd.mat.Empty() {
+		return fmt.Errorf("no frame to dump")
+	}
+
+	if err := os.MkdirAll(dumpDir, 0755); err != nil {
+		return fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	now := time.Now()
+	base := filepath.Join(dumpDir, fmt.Sprintf("%s_%s", now.Format("20060102_150405.000"), reason))
+
+	if !gocv.IMWrite(base+".png", *cd.mat) {
+		return fmt.Errorf("failed to write dump image %s.png", base)
+	}
+
+	info := dumpInfo{
+		Reason:  reason,
+		Time:    now,
+		Width:   cd.mat.Cols(),
+		Height:  cd.mat.Rows(),
+		MyStats: newDumpStats(&cd.MyStats),
+		Target:  newDumpStats(&cd.Target),
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal dump info: %w", err)
+	}
+	if err := os.WriteFile(base+".json", data, 0644); err != nil {
+		return fmt.Errorf("failed to write dump info: %w", err)
+	}
+
+	cd.Config.Log("Saved detection dump %s", base)
+	pruneDumps(limit)
+	return nil
+}
+
+// pruneDumps removes the oldest dumps until at most limit remain
+func pruneDumps(limit int) {
+	if limit <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(dumpDir)
+	if err != nil {
+		return
+	}
+
+	// Dump names start with a timestamp, so name order is age order
+	seen := make(map[string]bool)
+	var bases []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		base := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if !seen[base] {
+			seen[base] = true
+			bases = append(bases, base)
+		}
+	}
+	sort.Strings(bases)
+
+	for len(bases) > limit {
+		os.Remove(filepath.Join(dumpDir, bases[0]+".png"))
+		os.Remove(filepath.Join(dumpDir, bases[0]+".json"))
+		bases = bases[1:]
+	}
+}
+
+// dumpBarMask writes barDumpDir/<name>.png: the bar ROI enlarged barDumpScale
+// times with every pixel matching the bar color painted magenta, and the left
+// (green) and right (red) edge of the detected bar marked. Diagnostic only, the
+// detection result is not affected.
+func dumpBarMask(roi, mask gocv.Mat, bar image.Rectangle, name string) error {
+	if err := os.MkdirAll(barDumpDir, 0755); err != nil {
+		return fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	annotated := roi.Clone()
+	defer annotated.Close()
+	matched := gocv.NewMatWithSize(roi.Rows(), roi.Cols(), roi.Type())
+	defer matched.Close()
+	matched.SetTo(gocv.NewScalar(255, 0, 255, 0))
+	matched.CopyToWithMask(&annotated, mask)
+
+	scaled := gocv.NewMat()
+	defer scaled.Close()
+	gocv.Resize(annotated, &scaled, image.Point{}, barDumpScale, barDumpScale, gocv.InterpolationNearestNeighbor)
+
+	if !bar.Empty() {
+		minX := bar.Min.X * barDumpScale
+		maxX := bar.Max.X*barDumpScale - 1
+		gocv.Line(&scaled, image.Pt(minX, 0), image.Pt(minX, scaled.Rows()), color.RGBA{0, 255, 0, 255}, 1)
+		gocv.Line(&scaled, image.Pt(maxX, 0), image.Pt(maxX, scaled.Rows()), color.RGBA{255, 0, 0, 255}, 1)
+	}
+
+	path := filepath.Join(barDumpDir, name+".png")
+	if !gocv.IMWrite(path, scaled) {
+		return fmt.Errorf("failed to write %s", path)
+	}
+	return nil
+}
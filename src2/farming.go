@@ -6,8 +6,12 @@ package main
 
 import (
 	"fmt"
+	"image"
+	"math"
 	"math/rand"
 	"time"
+
+	"gocv.io/x/gocv"
 )
 
 // Stage represents the current state of the farming behavior
@@ -60,36 +64,145 @@ type RetryState struct {
 	Target          int // Number of times target not detected consecutively
 	Map             int // Number of times map not detected
 	OfflineKeyEvent int // Offline key event counter (1-30: Enter, 31-40: Escape)
+	Offline         int // Offline recovery attempts since the last kill
+	TargetKey       int // Consecutive nearest-target key presses that selected nothing
+	OfflineScreen   int // Login/character select steps taken in the current offline recovery
 }
 
 // SearchingEnemyState tracks searching behavior
 type SearchingEnemyState struct {
-	UpAndDown   int       // 1-3: looking down, 4-6: looking up
-	Reverse     bool      // true: turn left, false: turn right
-	Count       int       // Remaining rotation count
-	Wander      int       // Wander counter
-	ForwardTime time.Time // Time when started moving forward
-	Careful     bool      // Careful mode when too many mobs
+	UpAndDown   int          // 1-3: looking down, 4-6: looking up
+	Reverse     bool         // true: turn left, false: turn right
+	Count       int          // Remaining rotation count
+	Wander      int          // Wander counter
+	ForwardTime time.Time    // Time when started moving forward
+	Careful     bool         // Careful mode when too many mobs
+	TargetKey   time.Time    // Time the nearest-target key was pressed (zero if not waiting for a target)
+	Clicked     []ClickedMob // Recently clicked nameplates, skipped so the search rotates through mobs
+	Selected    *image.Point // Estimated heatmap cell of the last clicked or key-selected mob (nil = none)
+}
+
+// ClickedMob is a nameplate center clicked while searching
+type ClickedMob struct {
+	X    int
+	Y    int
+	Time time.Time
+}
+
+// targetKeyTimeout is how long to wait for the target bar after pressing the nearest-target key
+const targetKeyTimeout = 500 * time.Millisecond
+
+// Recently clicked nameplates are skipped for clickedMobCooldown; a mob within
+// clickedMobRadius pixels of a clicked center counts as the same mob
+const (
+	clickedMobCooldown = 3 * time.Second
+	clickedMobRadius   = 20
+)
+
+// recentlyClicked reports whether a nameplate center was clicked within the
+// cooldown, dropping expired entries
+func (s *SearchingEnemyState) recentlyClicked(x, y int) bool {
+	active := s.Clicked[:0]
+	clicked := false
+	for _, c := range s.Clicked {
+		if time.Since(c.Time) >= clickedMobCooldown {
+			continue
+		}
+		active = append(active, c)
+		dx, dy := c.X-x, c.Y-y
+		if dx*dx+dy*dy <= clickedMobRadius*clickedMobRadius {
+			clicked = true
+		}
+	}
+	s.Clicked = active
+	return clicked
 }
 
 // TargetState tracks current target information
 type TargetState struct {
-	LastHP       int       // Last recorded HP
-	LastHPUpdate time.Time // Last time HP was updated
+	LastHP       int          // Last recorded HP
+	LastHPUpdate time.Time    // Last time HP was updated
+	Cell         *image.Point // Estimated heatmap cell of the target (nil = unknown, e.g. it attacked first)
 }
 
+// HPRateState tracks how fast the player's HP changes
+type HPRateState struct {
+	SampleHP   int       // HP at SampleTime
+	SampleTime time.Time // Start of the current measurement window (zero if no sample)
+	Rate       float64   // Last measured HP loss in %/s (negative when healing)
+}
+
+// hpRateWindow is the minimum time between two HP samples, shorter windows are too noisy
+const hpRateWindow = 500 * time.Millisecond
+
 // ObstacleState tracks obstacle avoidance
 type ObstacleState struct {
 	Count int // Number of obstacle avoidance attempts
 }
 
+// NavigationState tracks minimap navigation
+type NavigationState struct {
+	StartTime  time.Time   // Time when navigation started (zero if not navigating)
+	TurnKey    string      // Arrow key currently held for turning ("" if none)
+	Forward    bool        // Whether "w" is held for moving forward
+	Heatmap    bool        // Navigating towards HeatTarget instead of the live minimap direction
+	HeatTarget image.Point // Heatmap cell being navigated to
+	Return     bool        // Walking back to the spot of death after respawning (implies Heatmap)
+}
+
+// EscapeState tracks the current escape
+type EscapeState struct {
+	StartTime time.Time // Time when the escape started (zero if not escaping)
+	TurnKey   string    // Arrow key held to turn toward open space ("" if none)
+}
+
+// navigateMsPerDegree is how long an arrow key is held per degree of rotation
+const navigateMsPerDegree = 5
+
+// StuckState tracks whether the player actually moves while holding forward
+type StuckState struct {
+	Reference *gocv.Mat // Minimap snapshot from the last time the player moved (nil if not sampling)
+	MovedAt   time.Time // Last time the minimap moved more than stuckMoveThreshold
+	SampledAt time.Time // Last time the minimap was sampled
+	Count     int       // Stuck events in a row, each one turns further
+	LastStuck time.Time // Time of the last stuck event
+	TurnKey   string    // Arrow key held to break free ("" if not turning)
+}
+
+const (
+	stuckSampleInterval = 500 * time.Millisecond // Time between minimap samples
+	stuckMoveThreshold  = 2.0                    // Minimap shift (pixels) that counts as moving
+	stuckTurnStep       = 45                     // Degrees turned per stuck event in a row
+	stuckMaxTurn        = 180                    // Largest turn (degrees)
+	stuckCountReset     = 15 * time.Second       // Time without being stuck before the turn shrinks again
+)
+
+// resetSampling drops the minimap reference, the stuck count is kept
+func (s *StuckState) resetSampling() {
+	if s.Reference != nil {
+		s.Reference.Close()
+		s.Reference = nil
+	}
+	s.MovedAt = time.Time{}
+	s.SampledAt = time.Time{}
+}
+
 // Farming implements the farming behavior
 type Farming struct {
 	Stage          Stage
 	Retry          RetryState
 	SearchingEnemy SearchingEnemyState
 	Target         TargetState
+	HPRate         HPRateState
 	Obstacle       ObstacleState
+	Navigation     NavigationState
+	Escape         EscapeState
+	Stuck          StuckState
+	RecoveredAt    time.Time // Time of the last offline recovery attempt (re-arms the watchdog)
+	Heatmap        *HeatmapTracker
+	Direction      DirectionInfo  // Minimap analysis of the current frame (detected once per loop)
+	Avoidance      *AvoidanceList // Heatmap cells with unreachable targets
+	FarmSpot       *image.Point   // Heatmap cell of the last death, walked back to after respawning (nil = none)
 	Config         *Config
 	Browser        *Browser
 	Detector       *ClientDetect // To be implemented
@@ -97,17 +210,127 @@ type Farming struct {
 
 // NewFarming creates a new farming behavior
 func NewFarming(cfg *Config, browser *Browser, detector *ClientDetect) *Farming {
+	stat := cfg.Snapshot()
 	return &Farming{
-		Stage:    StageInitializing,
-		Config:   cfg,
-		Browser:  browser,
-		Detector: detector,
+		Stage:     StageInitializing,
+		Heatmap:   NewHeatmapTracker(stat.HeatmapPath, time.Duration(stat.Settings.HeatmapHalfLife)*time.Minute),
+		Avoidance: NewAvoidanceList(stat.AvoidancePath, time.Duration(stat.Settings.AvoidanceTTL)*time.Minute),
+		Config:    cfg,
+		Browser:   browser,
+		Detector:  detector,
 	}
 }
 
+// mobCell returns the estimated heatmap cell of a mob: the player's cell plus the
+// nameplate's offset from the screen center, where the player stands
+func (f *Farming) mobCell(mob MobsPosition) image.Point {
+	screen := f.Detector.ScreenSize()
+	right := float64((mob.MinX+mob.MaxX)/2 - screen.X/2)
+	ahead := float64(screen.Y/2 - (mob.MinY+mob.MaxY)/2)
+	return f.Heatmap.ScreenCell(right, ahead)
+}
+
+// nearestMob returns the mob closest to the screen center (what the
+// nearest-target key selects), nil if there is none
+func (f *Farming) nearestMob() *MobsPosition {
+	screen := f.Detector.ScreenSize()
+	var nearest *MobsPosition
+	bestDistance := math.Inf(1)
+	for _, group := range [][]MobsPosition{f.Detector.Mobs.AggressiveMobs, f.Detector.Mobs.PassiveMobs, f.Detector.Mobs.VioletMobs} {
+		for i := range group {
+			dx := float64((group[i].MinX+group[i].MaxX)/2 - screen.X/2)
+			dy := float64((group[i].MinY+group[i].MaxY)/2 - screen.Y/2)
+			if distance := math.Hypot(dx, dy); distance < bestDistance {
+				nearest = &group[i]
+				bestDistance = distance
+			}
+		}
+	}
+	return nearest
+}
+
+// selectMob remembers the estimated heatmap cell of a mob being clicked or
+// key-selected, so it can be avoided if it turns out unreachable
+func (f *Farming) selectMob(mob *MobsPosition) {
+	if mob == nil {
+		f.SearchingEnemy.Selected = nil
+		return
+	}
+	cell := f.mobCell(*mob)
+	f.SearchingEnemy.Selected = &cell
+}
+
+// avoidTarget puts the target's estimated world position on the avoidance list
+// after it could not be reached. Mobs seen there later are ignored until the entry expires.
+func (f *Farming) avoidTarget() {
+	cell := f.Target.Cell
+	f.Target.Cell = nil
+	if cell == nil {
+		f.Config.Log("Unreachable target was not selected by the search, not avoiding its spot")
+		return
+	}
+	if err := f.Avoidance.Add(*cell); err != nil {
+		f.Config.Log("Failed to save avoidance list: %v", err)
+		return
+	}
+	f.Config.Log("Avoiding spot (%d,%d) for %v", cell.X, cell.Y, f.Avoidance.TTL)
+}
+
+// dropAvoidedMobs removes mobs standing in avoided cells from the detected mobs
+func (f *Farming) dropAvoidedMobs() {
+	keep := func(mobs []MobsPosition) []MobsPosition {
+		kept := mobs[:0]
+		for _, mob := range mobs {
+			if !f.Avoidance.Avoided(f.mobCell(mob)) {
+				kept = append(kept, mob)
+			}
+		}
+		return kept
+	}
+	mobs := &f.Detector.Mobs
+	mobs.AggressiveMobs = keep(mobs.AggressiveMobs)
+	mobs.PassiveMobs = keep(mobs.PassiveMobs)
+	mobs.VioletMobs = keep(mobs.VioletMobs)
+}
+
+// pickMob returns the first mob (aggressive, then passive, then violet) that was
+// not clicked recently, with its index in Status.Mobs. When every mob was clicked
+// recently the list is cleared and the first mob is picked again.
+func (f *Farming) pickMob() (*MobsPosition, int) {
+	mobs := f.Detector.Mobs
+	groups := [][]MobsPosition{mobs.AggressiveMobs, mobs.PassiveMobs, mobs.VioletMobs}
+	names := []string{"aggressive", "passive", "violet"}
+
+	var first *MobsPosition
+	firstGroup := ""
+	index := 0
+	for g, group := range groups {
+		for i := range group {
+			mob := &group[i]
+			if first == nil {
+				first = mob
+				firstGroup = names[g]
+			}
+			if !f.SearchingEnemy.recentlyClicked((mob.MinX+mob.MaxX)/2, (mob.MinY+mob.MaxY)/2) {
+				f.Config.Log("Clicking on %s mob #%d", names[g], index)
+				return mob, index
+			}
+			index++
+		}
+	}
+
+	if first == nil {
+		return nil, -1
+	}
+	f.SearchingEnemy.Clicked = nil
+	f.Config.Log("All mobs clicked recently, clicking on %s mob #0 again", firstGroup)
+	return first, 0
+}
+
 // Restore handles HP/MP/FP restoration and buff management
 func (f *Farming) Restore() {
 	cfg := f.Config
+	stat := cfg.Snapshot()
 
 	// Check if state bar is open
 	if !f.Detector.MyStats.Open {
@@ -119,13 +342,25 @@ func (f *Farming) Restore() {
 	// Check if player is dead
 	if !f.Detector.MyStats.Alive {
 		cfg.Log("Player is dead")
+		if f.Stage != StageDead {
+			f.dump("dead")
+			if stat.Settings.ReturnToFarmAfterDeath {
+				// Dying on the way back keeps the original spot
+				cell := f.Heatmap.Cell()
+				if f.Navigation.Return {
+					cell = f.Navigation.HeatTarget
+				}
+				f.FarmSpot = &cell
+			}
+		}
 		f.Stage = StageDead
 		return
 	}
 
-	// Check if disconnected (no kills for a long time)
-	if time.Since(cfg.Status.Player.LastKilledTime).Seconds() > float64(cfg.Stat.Settings.WatchDogTime) {
-		cfg.Log("WatchDog timeout: No kills for %d seconds", cfg.Stat.Settings.WatchDogTime)
+	// Check if disconnected (no kills for a long time, counted from the last recovery attempt)
+	sinceKill := min(time.Since(cfg.Status.Player.LastKilledTime), time.Since(f.RecoveredAt))
+	if f.Stage != StageOffline && sinceKill.Seconds() > float64(stat.Settings.WatchDogTime) {
+		cfg.Log("WatchDog timeout: No kills for %d seconds", stat.Settings.WatchDogTime)
 		f.Stage = StageOffline
 		return
 	}
@@ -140,9 +375,20 @@ func (f *Farming) Restore() {
 	cfg.Status.Player.MP = f.Detector.MyStats.MP.Value
 	cfg.Status.Player.FP = f.Detector.MyStats.FP.Value
 
-	// HP restoration
-	if f.Detector.MyStats.HP.Value < 100 {
-		// Try to use food
+	// Emergency: HP critical or dropping faster than healing can keep up.
+	// Skips the food-first order: pill right away (ignoring its threshold), then escape.
+	if f.updateHPRate(cfg.Status.Player.HP) {
+		page, slot := cfg.GetAvailableSlot(SlotTypePill, 0)
+		if page != -1 || slot != -1 {
+			f.UseSlot(page, slot)
+			cfg.AddAction(fmt.Sprintf("emergency_pill(%d:%d)", page, slot))
+		}
+		if f.Stage != StageEscaping {
+			cfg.Log("HP critical (%d%%, losing %.1f%%/s), escaping!", cfg.Status.Player.HP, f.HPRate.Rate)
+			f.Stage = StageEscaping
+		}
+	} else if f.Detector.MyStats.HP.Value < 100 {
+		// Normal HP restoration, try food first
 		page, slot := cfg.GetAvailableSlot(SlotTypeFood, cfg.Status.Player.HP)
 		if page != -1 || slot != -1 {
 			f.UseSlot(page, slot)
@@ -153,7 +399,7 @@ func (f *Farming) Restore() {
 			if page != -1 || slot != -1 {
 				f.UseSlot(page, slot)
 				cfg.AddAction(fmt.Sprintf("use_pill(%d:%d)", page, slot))
-			} else if cfg.Status.Player.HP < cfg.Stat.Attack.EscapeHP {
+			} else if cfg.Status.Player.HP < stat.Attack.EscapeHP {
 				// HP too low and restoration on cooldown, escape!
 				cfg.Log("HP too low (%d%%), escaping!", cfg.Status.Player.HP)
 				f.Stage = StageEscaping
@@ -187,6 +433,30 @@ func (f *Farming) Restore() {
 	}
 }
 
+// updateHPRate samples the player's HP and reports whether it is below CriticalHP
+// or dropping faster than CriticalHPRate
+func (f *Farming) updateHPRate(hp int) bool {
+	attack := f.Config.Snapshot().Attack
+
+	// 0 is a failed reading (death is handled before restoring)
+	if hp <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	if f.HPRate.SampleTime.IsZero() {
+		f.HPRate.SampleHP, f.HPRate.SampleTime = hp, now
+	} else if elapsed := now.Sub(f.HPRate.SampleTime); elapsed >= hpRateWindow {
+		f.HPRate.Rate = float64(f.HPRate.SampleHP-hp) / elapsed.Seconds()
+		f.HPRate.SampleHP, f.HPRate.SampleTime = hp, now
+	}
+
+	if attack.CriticalHP > 0 && hp < attack.CriticalHP {
+		return true
+	}
+	return attack.CriticalHPRate > 0 && f.HPRate.Rate > float64(attack.CriticalHPRate)
+}
+
 // AfterEnemyKill handles post-kill actions (pickup, pet)
 func (f *Farming) AfterEnemyKill() {
 	cfg := f.Config
@@ -197,9 +467,10 @@ func (f *Farming) AfterEnemyKill() {
 		// Increment kill count
 		cfg.AddKilled()
 		cfg.Log("Killed mob! Total: %d", cfg.Status.Player.Killed)
+		f.Retry.Offline = 0
 
 		// Setup wait for defeat interval
-		cfg.SetupWaitCtx("AfterEnemyKill", cfg.Stat.Attack.DefeatInterval)
+		cfg.SetupWaitCtx("AfterEnemyKill", cfg.Snapshot().Attack.DefeatInterval)
 
 	case 2:
 		// Try to use pet for pickup
@@ -231,6 +502,7 @@ func (f *Farming) AfterEnemyKill() {
 // Attacking handles the attack logic
 func (f *Farming) Attacking() {
 	cfg := f.Config
+	stat := cfg.Snapshot()
 
 	// Check if target exists and is open
 	hasTarget := f.Detector.Target.Open && f.Detector.Target.Alive
@@ -274,7 +546,7 @@ func (f *Farming) Attacking() {
 
 	// Check for obstacle (HP not changing for a long time)
 	timeSinceLastUpdate := time.Since(f.Target.LastHPUpdate).Milliseconds()
-	if timeSinceLastUpdate > int64(cfg.Stat.Attack.ObstacleThresholdTime) {
+	if timeSinceLastUpdate > int64(stat.Attack.ObstacleThresholdTime) {
 		cfg.Log("Obstacle detected: HP not changing for %dms", timeSinceLastUpdate)
 
 		if f.Detector.Target.HP.Value == 100 {
@@ -282,15 +554,16 @@ func (f *Farming) Attacking() {
 			cfg.Log("Never hit target, canceling")
 			f.Browser.SendKey("Escape", "press")
 			cfg.AddAction("cancel_obstacle_target")
+			f.avoidTarget()
 			f.Stage = StageSearchingForEnemy
 			f.Obstacle.Count = 0
 			return
-		} else if f.Obstacle.Count < cfg.Stat.Attack.ObstacleAvoidCount {
+		} else if f.Obstacle.Count < stat.Attack.ObstacleAvoidCount {
 			// Try to avoid obstacle using state machine
 			obstacleStage := cfg.SwitchWaitCtx("ObstacleAvoid")
 			switch obstacleStage {
 			case 1:
-				cfg.Log("Avoiding obstacle (attempt %d/%d)", f.Obstacle.Count, cfg.Stat.Attack.ObstacleAvoidCount)
+				cfg.Log("Avoiding obstacle (attempt %d/%d)", f.Obstacle.Count, stat.Attack.ObstacleAvoidCount)
 				f.Browser.SendKey("w", "press")
 				cfg.SetupWaitCtx("ObstacleAvoid", 100)
 
@@ -312,7 +585,7 @@ func (f *Farming) Attacking() {
 				}
 				f.Obstacle.Count++
 				f.Target.LastHPUpdate = time.Now() // Reset update time
-				cfg.SetupWaitCtx("ObstacleAvoid", cfg.Stat.Attack.ObstacleCoolDown)
+				cfg.SetupWaitCtx("ObstacleAvoid", stat.Attack.ObstacleCoolDown)
 
 			case 4:
 				// Obstacle avoidance complete
@@ -328,6 +601,7 @@ func (f *Farming) Attacking() {
 			cfg.Log("Obstacle avoidance failed, giving up")
 			f.Browser.SendKey("Escape", "press")
 			cfg.AddAction("give_up_obstacle")
+			f.avoidTarget()
 			f.Stage = StageSearchingForEnemy
 			f.Obstacle.Count = 0
 			return
@@ -336,8 +610,8 @@ func (f *Farming) Attacking() {
 
 	// Check attack timeout
 	attackDuration := time.Since(cfg.Status.Attack.AttackTime).Seconds()
-	if attackDuration > float64(cfg.Stat.Attack.MaxTime) {
-		cfg.Log("Attack timeout (%ds), giving up", cfg.Stat.Attack.MaxTime)
+	if attackDuration > float64(stat.Attack.MaxTime) {
+		cfg.Log("Attack timeout (%ds), giving up", stat.Attack.MaxTime)
 		f.Browser.SendKey("Escape", "press")
 		cfg.AddAction("timeout_give_up")
 		f.Stage = StageSearchingForEnemy
@@ -363,10 +637,28 @@ func (f *Farming) Attacking() {
 // SearchingForEnemy handles the enemy search logic
 func (f *Farming) SearchingForEnemy() {
 	cfg := f.Config
+	stat := cfg.Snapshot()
+
+	// Walk back to where we died before farming at the respawn point
+	if f.FarmSpot != nil {
+		_, distance := f.Heatmap.DirectionTo(*f.FarmSpot)
+		cfg.Log("Returning to farming spot (%d,%d), %.0fpx away", f.FarmSpot.X, f.FarmSpot.Y, distance)
+		cfg.AddAction("return_to_farm")
+		f.Navigation.Heatmap = true
+		f.Navigation.Return = true
+		f.Navigation.HeatTarget = *f.FarmSpot
+		f.Navigation.StartTime = time.Time{}
+		f.FarmSpot = nil
+		f.Stage = StageNavigating
+		return
+	}
 
 	// Get target and mobs info from Detector
 	hasTarget := f.Detector.Target.Open && f.Detector.Target.Alive
 
+	// Mobs at avoided spots could not be reached before, leave them out
+	f.dropAvoidedMobs()
+
 	// Count total mobs (aggressive + passive + violet)
 	mobsCount := len(f.Detector.Mobs.AggressiveMobs) +
 		len(f.Detector.Mobs.PassiveMobs) +
@@ -386,6 +678,7 @@ func (f *Farming) SearchingForEnemy() {
 		mobStr := fmt.Sprintf("(%d,%d,%d,%d,violet)", mob.MinX, mob.MinY, mob.MaxX-mob.MinX, mob.MaxY-mob.MinY)
 		cfg.Status.Mobs = append(cfg.Status.Mobs, mobStr)
 	}
+	cfg.Status.TargetMob = -1
 
 	// If target exists
 	if hasTarget {
@@ -396,10 +689,16 @@ func (f *Farming) SearchingForEnemy() {
 			return
 		}
 
+		// Target acquired, the nearest-target key works again
+		f.Retry.TargetKey = 0
+		f.SearchingEnemy.TargetKey = time.Time{}
+
 		// Initialize attack parameters
 		cfg.Status.Attack.AttackTime = time.Now()
 		f.Target.LastHP = 100
 		f.Target.LastHPUpdate = time.Now()
+		f.Target.Cell = f.SearchingEnemy.Selected
+		f.SearchingEnemy.Selected = nil
 		f.Obstacle.Count = 0
 		f.Stage = StageAttacking
 		cfg.Log("Target acquired, starting attack")
@@ -424,25 +723,41 @@ func (f *Farming) SearchingForEnemy() {
 			f.SearchingEnemy.ForwardTime = time.Time{}
 		}
 
-		// Click on mob (prioritize aggressive, then passive, then violet)
-		var targetMob *MobsPosition
-		if len(f.Detector.Mobs.AggressiveMobs) > 0 {
-			targetMob = &f.Detector.Mobs.AggressiveMobs[0]
-			cfg.Log("Clicking on aggressive mob")
-		} else if len(f.Detector.Mobs.PassiveMobs) > 0 {
-			targetMob = &f.Detector.Mobs.PassiveMobs[0]
-			cfg.Log("Clicking on passive mob")
-		} else if len(f.Detector.Mobs.VioletMobs) > 0 {
-			targetMob = &f.Detector.Mobs.VioletMobs[0]
-			cfg.Log("Clicking on violet mob")
+		// Select the nearest mob with the target key, verified by the target bar appearing
+		settings := stat.Settings
+		if settings.TargetingMode == TargetingModeNearestKey && f.Retry.TargetKey < settings.TargetKeyRetries {
+			if f.SearchingEnemy.TargetKey.IsZero() {
+				f.Browser.SendKey(settings.TargetKey, "press")
+				cfg.AddAction("target_nearest")
+				f.selectMob(f.nearestMob())
+				f.SearchingEnemy.TargetKey = time.Now()
+				return
+			}
+			if time.Since(f.SearchingEnemy.TargetKey) < targetKeyTimeout {
+				return
+			}
+
+			// No target bar (an acquired target is handled above)
+			f.SearchingEnemy.TargetKey = time.Time{}
+			f.Retry.TargetKey++
+			cfg.Log("Target key selected nothing (%d/%d)", f.Retry.TargetKey, settings.TargetKeyRetries)
+			if f.Retry.TargetKey >= settings.TargetKeyRetries {
+				cfg.Log("Target key keeps failing, falling back to clicking mobs")
+			}
+			return
 		}
 
-		if targetMob != nil {
+		// Click on mob (prioritize aggressive, then passive, then violet),
+		// skipping recently clicked ones so a failed click doesn't repeat forever
+		if targetMob, index := f.pickMob(); targetMob != nil {
 			// Click on center of mob
 			x := (targetMob.MinX + targetMob.MaxX) / 2
 			y := (targetMob.MinY + targetMob.MaxY) / 2
 			f.Browser.SimpleClick(x, y)
 			cfg.AddAction(fmt.Sprintf("click_mob(%d,%d)", x, y))
+			f.selectMob(targetMob)
+			cfg.Status.TargetMob = index
+			f.SearchingEnemy.Clicked = append(f.SearchingEnemy.Clicked, ClickedMob{X: x, Y: y, Time: time.Now()})
 		}
 		return
 	}
@@ -472,9 +787,15 @@ func (f *Farming) SearchingForEnemy() {
 			cfg.AddAction("look_up")
 			f.SearchingEnemy.Count = rand.Intn(6) + 7 // 7-12
 			f.SearchingEnemy.UpAndDown++
-		} else if cfg.Stat.Navigate {
-			// Navigation enabled
-			cfg.Log("Entering navigation mode")
+		} else if stat.Navigate {
+			// Navigation enabled, prefer a historically dense spot nearby
+			if cell, ok := f.Heatmap.Best(stat.Settings.HeatmapRadius, f.Avoidance.Avoided); ok {
+				cfg.Log("Entering navigation mode towards heatmap cell (%d,%d)", cell.X, cell.Y)
+				f.Navigation.Heatmap = true
+				f.Navigation.HeatTarget = cell
+			} else {
+				cfg.Log("Entering navigation mode")
+			}
 			f.Stage = StageNavigating
 		} else {
 			// Move forward
@@ -525,7 +846,252 @@ func (f *Farming) SearchingForEnemy() {
 	}
 }
 
-// Escaping handles escape from danger
+// Navigating moves towards the minimap direction with the most monsters
+func (f *Farming) Navigating() {
+	cfg := f.Config
+	stat := cfg.Snapshot()
+
+	if f.Navigation.StartTime.IsZero() {
+		f.Navigation.StartTime = time.Now()
+	}
+
+	// Mobs on screen, let SearchingForEnemy pick one (unless walking back to
+	// the farming spot, mobs on the way are ignored). Mobs at avoided spots don't count.
+	f.dropAvoidedMobs()
+	mobsCount := len(f.Detector.Mobs.AggressiveMobs) +
+		len(f.Detector.Mobs.PassiveMobs) +
+		len(f.Detector.Mobs.VioletMobs)
+	if mobsCount > 0 && !f.Navigation.Return {
+		cfg.Log("Navigation found %d mobs", mobsCount)
+		f.stopNavigating()
+		return
+	}
+
+	// Don't run into a wall forever
+	maxTime := stat.Settings.NavigateTime
+	if f.Navigation.Return {
+		maxTime = stat.Settings.ReturnMaxTime
+	}
+	if time.Since(f.Navigation.StartTime).Seconds() > float64(maxTime) {
+		if f.Navigation.Return {
+			_, distance := f.Heatmap.DirectionTo(f.Navigation.HeatTarget)
+			cfg.Log("Return to farming spot failed: timeout (%ds), %.0fpx left", maxTime, distance)
+		} else {
+			cfg.Log("Navigation timeout (%ds), searching again", maxTime)
+		}
+		cfg.AddAction("navigate_timeout")
+		f.stopNavigating()
+		return
+	}
+
+	stage := cfg.SwitchWaitCtx("Navigating")
+	switch stage {
+	case 1:
+		// Turn towards the best direction, hold time proportional to the angle delta
+		direction := f.Direction
+		var delta float64
+		if f.Navigation.Heatmap {
+			angle, distance := f.Heatmap.DirectionTo(f.Navigation.HeatTarget)
+			if distance < heatmapCellSize/2 {
+				if f.Navigation.Return {
+					cfg.Log("Returned to farming spot (%d,%d) in %.0fs", f.Navigation.HeatTarget.X, f.Navigation.HeatTarget.Y, time.Since(f.Navigation.StartTime).Seconds())
+				} else {
+					cfg.Log("Reached heatmap cell (%d,%d)", f.Navigation.HeatTarget.X, f.Navigation.HeatTarget.Y)
+				}
+				cfg.AddAction("navigate_arrived")
+				f.stopNavigating()
+				return
+			}
+			delta = normalizeAngle(angle - direction.CurrentAngle)
+			cfg.Log("Navigating to heatmap cell: current %.0f, target %.0f (%.0fpx), turning %.0f", direction.CurrentAngle, angle, distance, delta)
+		} else if direction.Found {
+			delta = normalizeAngle(direction.BestAngle - direction.CurrentAngle)
+			cfg.Log("Navigating: current %.0f, best %.0f, turning %.0f", direction.CurrentAngle, direction.BestAngle, delta)
+		} else {
+			// No monsters on minimap, wander randomly
+			delta = float64(rand.Intn(181) - 90) // -90 to 90
+			cfg.Log("No monsters on minimap, wandering (turning %.0f)", delta)
+		}
+
+		if math.Abs(delta) < 10 {
+			cfg.SetupWaitCtx("Navigating", 0) // Already facing, go straight to stage 2
+			return
+		}
+
+		// Minimap angles grow clockwise (screen Y points down)
+		f.Navigation.TurnKey = "ArrowRight"
+		if delta < 0 {
+			f.Navigation.TurnKey = "ArrowLeft"
+		}
+		f.Browser.SendKey(f.Navigation.TurnKey, "hold")
+		cfg.AddAction(fmt.Sprintf("navigate_turn(%.0f)", delta))
+		cfg.SetupWaitCtx("Navigating", int(math.Abs(delta))*navigateMsPerDegree)
+
+	case 2:
+		// Stop turning, move forward
+		f.releaseTurnKey()
+		f.Browser.SendKey("w", "hold")
+		f.Navigation.Forward = true
+		cfg.AddAction("navigate_forward")
+		cfg.SetupWaitCtx("Navigating", stat.Settings.NavigateMove)
+
+	case 3:
+		// Stop and re-evaluate the direction on the next frame
+		f.Browser.SendKey("w", "release")
+		f.Navigation.Forward = false
+		cfg.SetupWaitCtx("Navigating", -1)
+
+	case -1:
+		// Still waiting
+		return
+	}
+}
+
+// releaseTurnKey releases the arrow key held for turning, if any
+func (f *Farming) releaseTurnKey() {
+	if f.Navigation.TurnKey != "" {
+		f.Browser.SendKey(f.Navigation.TurnKey, "release")
+		f.Navigation.TurnKey = ""
+	}
+}
+
+// stopNavigating releases held keys and returns to searching
+func (f *Farming) stopNavigating() {
+	f.releaseTurnKey()
+	f.Browser.SendKey("w", "release")
+	f.Config.SetupWaitCtx("Navigating", -1)
+	f.Navigation.StartTime = time.Time{}
+	f.Navigation.Forward = false
+	f.Navigation.Heatmap = false
+	f.Navigation.Return = false
+
+	// Search around before navigating again
+	f.SearchingEnemy.UpAndDown = 1
+	f.SearchingEnemy.Count = rand.Intn(6) + 7 // 7-12
+	f.Stage = StageSearchingForEnemy
+}
+
+// CheckStuck samples the minimap while moving forward and, when it has not moved
+// for StuckTime, turns and jumps to get free. Repeated stuck events turn further.
+func (f *Farming) CheckStuck(moving bool) {
+	cfg := f.Config
+
+	// Finish a turn in progress
+	if f.Stuck.TurnKey != "" {
+		if cfg.SwitchWaitCtx("Unstuck") == -1 {
+			return
+		}
+		f.Browser.SendKey(f.Stuck.TurnKey, "release")
+		f.Stuck.TurnKey = ""
+		cfg.SetupWaitCtx("Unstuck", -1)
+		f.Stuck.resetSampling()
+		return
+	}
+
+	stuckTime := cfg.Snapshot().Settings.StuckTime
+	if !moving || stuckTime <= 0 {
+		f.Stuck.resetSampling()
+		return
+	}
+
+	if time.Since(f.Stuck.SampledAt) < stuckSampleInterval {
+		return
+	}
+	f.Stuck.SampledAt = time.Now()
+
+	snapshot, ok := f.Detector.MinimapSnapshot()
+	if !ok {
+		return
+	}
+
+	// Keep the reference while the minimap stays put, so slow drift still adds up
+	shift, ok := 0.0, false
+	if f.Stuck.Reference != nil {
+		shift, ok = MinimapShift(*f.Stuck.Reference, snapshot)
+	}
+	if !ok || shift > stuckMoveThreshold {
+		if f.Stuck.Reference != nil {
+			f.Stuck.Reference.Close()
+		}
+		f.Stuck.Reference = &snapshot
+		f.Stuck.MovedAt = time.Now()
+		return
+	}
+	snapshot.Close()
+
+	still := time.Since(f.Stuck.MovedAt)
+	if still < time.Duration(stuckTime)*time.Millisecond {
+		return
+	}
+
+	if time.Since(f.Stuck.LastStuck) > stuckCountReset {
+		f.Stuck.Count = 0
+	}
+	f.Stuck.Count++
+	f.Stuck.LastStuck = time.Now()
+
+	turn := min(stuckTurnStep*f.Stuck.Count, stuckMaxTurn)
+	f.Stuck.TurnKey = "ArrowRight"
+	if rand.Intn(2) == 0 {
+		f.Stuck.TurnKey = "ArrowLeft"
+	}
+	cfg.Log("Stuck for %.1fs (minimap moved %.1fpx), turning %d and jumping (#%d)", still.Seconds(), shift, turn, f.Stuck.Count)
+
+	// Forward stays held, turning while jumping gets around most obstacles
+	f.Browser.SendKey(f.Stuck.TurnKey, "hold")
+	f.Browser.SendKey(" ", "press")
+	cfg.AddAction(fmt.Sprintf("unstuck(%d)", turn))
+	cfg.SetupWaitCtx("Unstuck", turn*navigateMsPerDegree)
+}
+
+// CheckPanic runs the configured panic action when more aggressive mobs than
+// PanicMobCount are on screen while searching, navigating or attacking
+func (f *Farming) CheckPanic() {
+	cfg := f.Config
+	stat := cfg.Snapshot()
+
+	limit := stat.Attack.PanicMobCount
+	if limit <= 0 || stat.Attack.PanicAction == PanicActionContinue {
+		return
+	}
+	if f.Stage != StageSearchingForEnemy && f.Stage != StageNavigating && f.Stage != StageAttacking {
+		return
+	}
+
+	count := len(f.Detector.Mobs.AggressiveMobs)
+	if count <= limit {
+		return
+	}
+
+	cfg.Log("Panic: %d aggressive mobs (limit %d), action %s", count, limit, stat.Attack.PanicAction)
+	if f.Stage == StageNavigating {
+		f.stopNavigating()
+	}
+	if !f.SearchingEnemy.ForwardTime.IsZero() {
+		f.Browser.SendKey("w", "release")
+		f.SearchingEnemy.ForwardTime = time.Time{}
+	}
+	// Drop the current target so the fight does not continue
+	f.Browser.SendKey("Escape", "press")
+
+	if stat.Attack.PanicAction == PanicActionTeleport {
+		page, slot := cfg.GetAvailableSlot(SlotTypeTeleport, 0)
+		if page != -1 || slot != -1 {
+			f.UseSlot(page, slot)
+			cfg.AddAction(fmt.Sprintf("panic_teleport(%d:%d)", page, slot))
+			f.Stage = StageInitializing
+			return
+		}
+		cfg.Log("No teleport slot ready, escaping instead")
+	}
+
+	cfg.AddAction("panic_escape")
+	f.Stage = StageEscaping
+}
+
+// Escaping handles escape from danger: turn toward open space (SmartEscape),
+// run forward, ride the board, then wait for EscapeResumeHP. EscapeMaxTime
+// caps the whole escape.
 func (f *Farming) Escaping() {
 	cfg := f.Config
 
@@ -533,13 +1099,44 @@ func (f *Farming) Escaping() {
 	switch stage {
 	case 1:
 		cfg.Log("Escaping from danger...")
+		f.Escape.StartTime = time.Now()
+		if !cfg.Snapshot().Attack.SmartEscape {
+			cfg.SetupWaitCtx("Escaping", 0) // Run straight ahead
+			return
+		}
+
+		// Turn toward the minimap sector with the fewest monsters
+		direction := f.Direction
+		delta := normalizeAngle(direction.OpenAngle() - direction.CurrentAngle)
+		if math.Abs(delta) < 10 {
+			cfg.Log("Escaping straight ahead, %d monsters on the minimap", len(direction.Monsters))
+			cfg.SetupWaitCtx("Escaping", 0)
+			return
+		}
+		cfg.Log("Escaping toward open space: current %.0f, turning %.0f (%d monsters on the minimap)", direction.CurrentAngle, delta, len(direction.Monsters))
+
+		// Minimap angles grow clockwise (screen Y points down)
+		f.Escape.TurnKey = "ArrowRight"
+		if delta < 0 {
+			f.Escape.TurnKey = "ArrowLeft"
+		}
+		f.Browser.SendKey(f.Escape.TurnKey, "hold")
+		cfg.AddAction(fmt.Sprintf("escape_turn(%.0f)", delta))
+		cfg.SetupWaitCtx("Escaping", int(math.Abs(delta))*navigateMsPerDegree)
+
+	case 2:
+		if f.Escape.TurnKey != "" {
+			f.Browser.SendKey(f.Escape.TurnKey, "release")
+			f.Escape.TurnKey = ""
+		}
+
 		// Press and hold forward
 		f.Browser.SendKey("w", "hold")
 		cfg.AddAction("escape_forward")
 		// Hold for 10 seconds
-		cfg.SetupWaitCtx("Escaping", 10000)
+		cfg.SetupWaitCtx("Escaping", f.escapeWait(10000))
 
-	case 2:
+	case 3:
 		// Release forward
 		f.Browser.SendKey("w", "release")
 		cfg.AddAction("escape_stop")
@@ -552,30 +1149,65 @@ func (f *Farming) Escaping() {
 		}
 
 		// Wait 20 seconds
-		cfg.SetupWaitCtx("Escaping", 20000)
+		cfg.SetupWaitCtx("Escaping", f.escapeWait(20000))
 
-	case 3:
+	case 4:
 		// Press board skill again to dismount
 		page, slot := cfg.GetAvailableSlot(SlotTypeBoard, 0)
 		if page != -1 || slot != -1 {
 			f.UseSlot(page, slot)
 			cfg.AddAction(fmt.Sprintf("escape_dismount(%d:%d)", page, slot))
 		}
-
-		// Clear wait context and switch to searching
-		cfg.SetupWaitCtx("Escaping", -1)
-		cfg.Log("Escape completed, searching for enemy")
-		f.Stage = StageSearchingForEnemy
+		f.finishEscape()
 
 	case -1:
 		// Still waiting
 		return
+
+	default:
+		// Waiting for HP to recover
+		f.finishEscape()
+	}
+}
+
+// escapeWait returns durationMS, shortened so the escape stays within EscapeMaxTime
+func (f *Farming) escapeWait(durationMS int) int {
+	stat := f.Config.Snapshot()
+	if stat.Attack.EscapeMaxTime <= 0 {
+		return durationMS
 	}
+	remaining := time.Duration(stat.Attack.EscapeMaxTime)*time.Second - time.Since(f.Escape.StartTime)
+	return max(0, min(durationMS, int(remaining.Milliseconds())))
+}
+
+// finishEscape switches to searching once HP reached EscapeResumeHP, or when
+// EscapeMaxTime is up; otherwise checks again in a second
+func (f *Farming) finishEscape() {
+	cfg := f.Config
+	stat := cfg.Snapshot()
+
+	hp := f.Detector.MyStats.HP.Value
+	resumeHP := stat.Attack.EscapeResumeHP
+	if hp < resumeHP && f.escapeWait(1000) > 0 {
+		cfg.Log("Escaped, waiting for HP to recover (%d%% < %d%%)", hp, resumeHP)
+		cfg.SetupWaitCtx("Escaping", 1000)
+		return
+	}
+	if hp < resumeHP {
+		cfg.Log("Escape time (%ds) is up with HP at %d%%, resuming anyway", stat.Attack.EscapeMaxTime, hp)
+	}
+
+	// Clear wait context and switch to searching
+	cfg.SetupWaitCtx("Escaping", -1)
+	f.Escape = EscapeState{}
+	cfg.Log("Escape completed, searching for enemy")
+	f.Stage = StageSearchingForEnemy
 }
 
 // Dead handles death and respawn
 func (f *Farming) Dead() {
 	cfg := f.Config
+	stat := cfg.Snapshot()
 
 	stage := cfg.SwitchWaitCtx("Dead")
 	switch stage {
@@ -584,20 +1216,21 @@ func (f *Farming) Dead() {
 		f.Browser.SendKey("Enter", "press")
 		cfg.AddAction("death_confirm")
 		// Setup wait for death confirm interval
-		cfg.SetupWaitCtx("Dead", cfg.Stat.Settings.DeathConfirm)
+		cfg.SetupWaitCtx("Dead", stat.Settings.DeathConfirm)
 
 	case 2:
 		// Death handling complete, check if alive
 		if f.Detector.MyStats.Alive {
 			cfg.Log("Respawned successfully")
 			cfg.SetupWaitCtx("Dead", -1) // Clear wait context
+			f.respawnedAtRespawnPoint()
 			f.Stage = StageInitializing
 		} else {
 			// Still dead, press Enter again
 			cfg.Log("Still dead, retrying...")
 			f.Browser.SendKey("Enter", "press")
 			cfg.AddAction("death_confirm_retry")
-			cfg.SetupWaitCtx("Dead", cfg.Stat.Settings.DeathConfirm)
+			cfg.SetupWaitCtx("Dead", stat.Settings.DeathConfirm)
 		}
 
 	case -1:
@@ -606,24 +1239,90 @@ func (f *Farming) Dead() {
 	}
 }
 
+// respawnedAtRespawnPoint moves the estimated position to the configured respawn
+// point, so the walk back to FarmSpot starts from where the player really is
+func (f *Farming) respawnedAtRespawnPoint() {
+	if f.FarmSpot == nil {
+		return
+	}
+
+	point := f.Config.Snapshot().Settings.RespawnPoint
+	if len(point) < 2 {
+		f.Config.Log("Respawn point not configured, not returning to the farming spot")
+		f.FarmSpot = nil
+		return
+	}
+	f.Heatmap.SetPosition(point[0], point[1])
+}
+
 // Offline handles disconnection recovery
 func (f *Farming) Offline() {
 	cfg := f.Config
+	stat := cfg.Snapshot()
 
 	stage := cfg.SwitchWaitCtx("Offline")
 	switch stage {
 	case 1:
-		cfg.Log("Handling offline state")
-		// Refresh browser
-		err := f.Browser.Refresh(cfg)
-		if err != nil {
-			cfg.Log("Failed to refresh browser: %v", err)
+		// Give up after WatchDogRetry attempts without a kill
+		if f.Retry.Offline >= stat.Settings.WatchDogRetry {
+			cfg.Log("Offline recovery failed %d times, stopping", f.Retry.Offline)
+			cfg.Disable()
+			cfg.SetupWaitCtx("Offline", -1)
+			return
+		}
+
+		// A login or character select screen needs clicks instead of key presses
+		screen, pos := f.detectScreen()
+		cfg.Log("Offline: %s screen detected", screen)
+		if f.Retry.OfflineScreen < offlineScreenSteps && f.handleOfflineScreen(screen, pos) {
+			f.Retry.OfflineScreen++
+			f.Retry.OfflineKeyEvent = 0 // Stage 2 detects the screen again
+			cfg.SetupWaitCtx("Offline", 5000)
+			return
+		}
+
+		strategy := f.offlineStrategy()
+		if f.Retry.OfflineScreen > 0 {
+			// Just logged back in, a refresh would throw the session away again
+			strategy = OfflineRecoveryReloadSoft
 		}
-		// Wait for page to load (5 seconds)
-		cfg.SetupWaitCtx("Offline", 5000)
+		cfg.Log("Handling offline state (attempt %d/%d, strategy %s)", f.Retry.Offline+1, stat.Settings.WatchDogRetry, strategy)
+		cfg.AddAction(fmt.Sprintf("offline_recovery(%s)", strategy))
 		f.Retry.OfflineKeyEvent = 1
 
+		switch strategy {
+		case OfflineRecoveryRefresh:
+			// Refresh browser
+			err := f.Browser.Refresh(cfg)
+			if err != nil {
+				cfg.Log("Failed to refresh browser: %v", err)
+			}
+			// Wait for page to load (5 seconds)
+			cfg.SetupWaitCtx("Offline", 5000)
+
+		case OfflineRecoveryReconnectButton:
+			pos, found := f.Detector.FindTemplate(stat.Settings.ReconnectTemplate, 0.8)
+			if found {
+				f.Browser.SimpleClick(pos.X, pos.Y)
+				cfg.AddAction(fmt.Sprintf("click_reconnect(%d,%d)", pos.X, pos.Y))
+				cfg.SetupWaitCtx("Offline", 5000)
+			} else {
+				cfg.Log("Reconnect button not found")
+				cfg.SetupWaitCtx("Offline", 0)
+			}
+
+		default:
+			// Soft reload: key presses only
+			cfg.SetupWaitCtx("Offline", 0)
+		}
+
 	case 2:
+		// Screen step done, detect the next screen from stage 1
+		if f.Retry.OfflineKeyEvent == 0 {
+			cfg.SetupWaitCtx("Offline", -1)
+			return
+		}
+
 		// Press Enter every second until state bar appears (1-30: Enter)
 		// Check if state bar is already open
 		if f.Detector.MyStats.Open {
@@ -656,10 +1355,13 @@ func (f *Farming) Offline() {
 		}
 
 	case 4:
-		// Reconnection attempt completed
+		// Reconnection attempt completed, the next failure escalates the strategy
 		cfg.Log("Reconnection attempt completed")
 		cfg.SetupWaitCtx("Offline", -1) // Clear wait context
 		f.Retry.OfflineKeyEvent = 0
+		f.Retry.OfflineScreen = 0
+		f.Retry.Offline++
+		f.RecoveredAt = time.Now()
 		f.Stage = StageInitializing
 
 	case -1:
@@ -668,6 +1370,98 @@ func (f *Farming) Offline() {
 	}
 }
 
+// Game screens told apart during offline recovery (Farming.detectScreen)
+const (
+	ScreenNoCanvas     = "no-canvas"        // Page blank or still loading
+	ScreenInGame       = "in-game"          // Status bar visible
+	ScreenDisconnected = "disconnected"     // In-game disconnected dialog (ReconnectTemplate)
+	ScreenLogin        = "login"            // Login screen (LoginTemplate)
+	ScreenCharSelect   = "character-select" // Character select screen (StartTemplate)
+	ScreenUnknown      = "unknown"
+)
+
+// offlineScreenSteps is the max login/character select clicks per offline
+// recovery attempt (a screen that never changes falls back to the strategies)
+const offlineScreenSteps = 4
+
+// detectScreen tells which screen the game shows, with the position of the
+// matched template (the button to click) for template-detected screens
+func (f *Farming) detectScreen() (string, image.Point) {
+	if !f.Browser.CheckCanvasExists() {
+		return ScreenNoCanvas, image.Point{}
+	}
+	if f.Detector.MyStats.Open {
+		return ScreenInGame, image.Point{}
+	}
+
+	settings := f.Config.Snapshot().Settings
+	screens := []struct {
+		screen   string
+		template string
+	}{
+		{ScreenDisconnected, settings.ReconnectTemplate},
+		{ScreenLogin, settings.LoginTemplate},
+		{ScreenCharSelect, settings.StartTemplate},
+	}
+	for _, s := range screens {
+		if !templateExists(s.template) {
+			continue
+		}
+		if pos, found := f.Detector.FindTemplate(s.template, 0.8); found {
+			return s.screen, pos
+		}
+	}
+	return ScreenUnknown, image.Point{}
+}
+
+// handleOfflineScreen clicks through a login, character select or disconnected
+// screen. pos is the detected button. Returns false if the screen needs the
+// regular recovery strategies instead.
+func (f *Farming) handleOfflineScreen(screen string, pos image.Point) bool {
+	cfg := f.Config
+
+	switch screen {
+	case ScreenDisconnected, ScreenLogin:
+		f.Browser.SimpleClick(pos.X, pos.Y)
+		cfg.AddAction(fmt.Sprintf("click_%s(%d,%d)", screen, pos.X, pos.Y))
+		return true
+
+	case ScreenCharSelect:
+		if path := cfg.Snapshot().Settings.CharacterTemplate; templateExists(path) {
+			if char, found := f.Detector.FindTemplate(path, 0.8); found {
+				f.Browser.SimpleClick(char.X, char.Y)
+				cfg.AddAction(fmt.Sprintf("click_character(%d,%d)", char.X, char.Y))
+			} else {
+				cfg.Log("Character not found on character select, keeping the selection")
+			}
+		}
+		f.Browser.SimpleClick(pos.X, pos.Y)
+		cfg.AddAction(fmt.Sprintf("click_start(%d,%d)", pos.X, pos.Y))
+		return true
+	}
+	return false
+}
+
+// offlineStrategy returns the recovery strategy for the current attempt.
+// The first attempt uses the configured strategy, each failed attempt escalates
+// one step along offlineRecoveryOrder.
+func (f *Farming) offlineStrategy() string {
+	cfg := f.Config
+
+	start := 0
+	for i, strategy := range offlineRecoveryOrder {
+		if strategy == cfg.Snapshot().Settings.OfflineRecovery {
+			start = i
+		}
+	}
+
+	index := min(start+f.Retry.Offline, len(offlineRecoveryOrder)-1)
+	if f.Retry.Offline > 0 && index > start && index == start+f.Retry.Offline {
+		cfg.Log("Escalating offline recovery: %s -> %s", offlineRecoveryOrder[index-1], offlineRecoveryOrder[index])
+	}
+	return offlineRecoveryOrder[index]
+}
+
 // Initializing checks if the game is ready
 func (f *Farming) Initializing() {
 	cfg := f.Config
@@ -695,6 +1489,8 @@ func (f *Farming) Initializing() {
 			cfg.Log("State bar not detected (retry %d)", f.Retry.State)
 
 			if f.Retry.State > 5 {
+				f.dump("state_bar")
+
 				// Try to open state bar by pressing 't'
 				f.Browser.SendKey("t", "press")
 				cfg.AddAction(fmt.Sprintf("open_state_bar(retry_%d)", f.Retry.State))
@@ -728,6 +1524,17 @@ func (f *Farming) UseSlot(page, slot int) error {
 	return f.Browser.SendKey(fmt.Sprintf("%d", slot), "press")
 }
 
+// dump saves a detection dump for debugging when debug mode is on
+func (f *Farming) dump(reason string) {
+	cfg := f.Config
+	if !cfg.GetDebug() {
+		return
+	}
+	if err := f.Detector.Dump(reason, cfg.Snapshot().Settings.DumpLimit); err != nil {
+		cfg.Log("Failed to save detection dump: %v", err)
+	}
+}
+
 // Start is the main farming loop
 func (f *Farming) Start() {
 	cfg := f.Config
@@ -738,15 +1545,24 @@ func (f *Farming) Start() {
 		frameStartTime := time.Now()
 
 		// Capture screenshot
-		img, err := f.Browser.Capture()
+		frame, err := f.Browser.Capture(cfg)
 		if err != nil {
 			cfg.Log("Failed to capture: %v", err)
 			cfg.WaitInterval(frameStartTime)
 			continue
 		}
 
+		// Skip frames that waited in the screencast queue past StaleFrameTimeout
+		// (e.g. while an action blocked the loop), they show an old screen
+		staleTimeout := time.Duration(cfg.Snapshot().Settings.StaleFrameTimeout) * time.Millisecond
+		if staleTimeout > 0 && frame.Age() > staleTimeout {
+			cfg.Log("Skipping stale frame (%v old)", frame.Age().Round(time.Millisecond))
+			cfg.WaitInterval(frameStartTime)
+			continue
+		}
+
 		// Update image in detector (converts to Mat internally)
-		err = f.Detector.UpdateImage(img)
+		err = f.Detector.UpdateImage(frame.Image)
 		if err != nil {
 			cfg.Log("Failed to update image: %v", err)
 			cfg.WaitInterval(frameStartTime)
@@ -758,13 +1574,28 @@ func (f *Farming) Start() {
 		f.Detector.UpdateTargetStats()
 
 		// Update mobs detection only when searching or navigating
-		if f.Stage == StageSearchingForEnemy || f.Stage == StageNavigating {
+		// (and while attacking when the panic check needs the mob count)
+		panicCheck := cfg.Snapshot().Attack.PanicMobCount > 0 && f.Stage == StageAttacking
+		if f.Stage == StageSearchingForEnemy || f.Stage == StageNavigating || panicCheck {
 			f.Detector.UpdateMobs()
 		}
 
+		// Track position and mob encounters on the heatmap
+		moving := f.Navigation.Forward || !f.SearchingEnemy.ForwardTime.IsZero()
+		f.Direction = f.Detector.DetectDirection()
+		f.Heatmap.Update(f.Direction, moving)
+		x, y := f.Heatmap.Position()
+		cfg.Status.Player.Position = [2]int{int(x), int(y)}
+
+		// Turn and jump when walking into a wall
+		f.CheckStuck(moving)
+
 		// Restore HP/MP/FP
 		f.Restore()
 
+		// Too many aggressive mobs, overrides searching and attacking
+		f.CheckPanic()
+
 		// Update stage to config
 		cfg.UpdateStage(f.Stage.String())
 
@@ -792,9 +1623,7 @@ func (f *Farming) Start() {
 			f.Offline()
 
 		case StageNavigating:
-			// TODO: Implement navigation logic
-			cfg.Log("Navigating...")
-			f.Stage = StageSearchingForEnemy
+			f.Navigating()
 		}
 
 		// Save status
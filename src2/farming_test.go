@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testConfig loads a default stat.json from a temporary directory, with every
+// file the bot writes kept in that directory
+func testConfig(t *testing.T) *Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	stat := defaultStat()
+	stat.StatusPath = filepath.Join(dir, "status.json")
+	stat.CookiesPath = filepath.Join(dir, "cookie.json")
+	stat.HeatmapPath = ""
+	stat.AvoidancePath = ""
+	stat.ActionsPath = ""
+	stat.LogPath = ""
+	stat.BrowserLogPath = ""
+
+	data, err := json.MarshalIndent(stat, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal stat: %v", err)
+	}
+	path := filepath.Join(dir, "stat.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write stat: %v", err)
+	}
+
+	cfg, err := InitConfig(path)
+	if err != nil {
+		t.Fatalf("InitConfig failed: %v", err)
+	}
+	return cfg
+}
+
+func TestOfflineGivesUp(t *testing.T) {
+	cfg := testConfig(t)
+	f := &Farming{Stage: StageOffline, Config: cfg}
+	f.Retry.Offline = cfg.Snapshot().Settings.WatchDogRetry
+
+	done := make(chan struct{})
+	go func() {
+		f.Offline()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Offline() did not return after the last recovery attempt (deadlock)")
+	}
+
+	if cfg.IsEnabled() {
+		t.Fatal("bot still enabled after offline recovery gave up")
+	}
+
+	// A stat.json reload must not undo the runtime stop
+	if err := cfg.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if cfg.IsEnabled() {
+		t.Error("reload re-enabled the bot after offline recovery gave up")
+	}
+}
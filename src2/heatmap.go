@@ -0,0 +1,241 @@
+// Package main - heatmap.go
+//
+// This file implements the mob density heatmap used to return to historically dense farming spots.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	heatmapCellSize       = 40.0            // Cell size in minimap pixels
+	heatmapMoveSpeed      = 6.0             // Estimated walking speed in minimap pixels per second
+	heatmapSampleInterval = 5 * time.Second // Min time between monster samples (avoids counting the same mobs every frame)
+	heatmapSaveInterval   = time.Minute     // Time between automatic saves
+	heatmapMinCount       = 3.0             // Min decayed count for a cell to be worth navigating to
+	heatmapPruneCount     = 0.1             // Cells decayed below this count are dropped
+	heatmapScreenScale    = 0.05            // Minimap pixels per screen pixel of a mob's offset from the player (rough, depends on the camera)
+)
+
+// HeatmapCell holds the monster encounter count of one grid cell
+type HeatmapCell struct {
+	Count   float64   `json:"count"`   // Encounter count at Updated (decays afterwards)
+	Updated time.Time `json:"updated"` // Last time Count was updated
+}
+
+// heatmapFile is the on-disk heatmap format. It is written for inspection only:
+// cells are keyed by dead reckoning from the session's start position, which
+// means nothing after a restart, so the file is never loaded back.
+type heatmapFile struct {
+	Cells map[string]*HeatmapCell `json:"cells"` // Key: "cellX,cellY"
+}
+
+// HeatmapTracker accumulates monster encounters on a grid keyed by an approximate
+// world position. The position is integrated from the minimap arrow direction
+// while walking forward, so it drifts over time; decay keeps the map adapted to
+// respawns and recent movement. The position starts at the origin each session
+// and is re-anchored at Settings.RespawnPoint after a respawn.
+type HeatmapTracker struct {
+	Path     string        // File the heatmap is written to ("" = not written)
+	HalfLife time.Duration // Time for a cell count to decay to half
+
+	X, Y  float64                 // Estimated player position in minimap pixels
+	Angle float64                 // Last player arrow direction in degrees (minimap coordinates)
+	Cells map[string]*HeatmapCell // Encounter counts by cell key
+
+	lastUpdate time.Time
+	lastSample time.Time
+	lastSave   time.Time
+	mu         sync.Mutex
+}
+
+// NewHeatmapTracker creates an empty heatmap tracker, overwriting the previous session's file
+func NewHeatmapTracker(path string, halfLife time.Duration) *HeatmapTracker {
+	h := &HeatmapTracker{
+		Path:     path,
+		HalfLife: halfLife,
+		Cells:    make(map[string]*HeatmapCell),
+		lastSave: time.Now(),
+	}
+	h.save()
+	return h
+}
+
+// cellOf returns the grid cell containing a position
+func cellOf(x, y float64) image.Point {
+	return image.Pt(int(math.Floor(x/heatmapCellSize)), int(math.Floor(y/heatmapCellSize)))
+}
+
+// cellKey returns the map key of a grid cell
+func cellKey(cell image.Point) string {
+	return fmt.Sprintf("%d,%d", cell.X, cell.Y)
+}
+
+// decayed returns the cell count decayed to now
+func (h *HeatmapTracker) decayed(cell *HeatmapCell, now time.Time) float64 {
+	if h.HalfLife <= 0 {
+		return cell.Count
+	}
+	age := now.Sub(cell.Updated)
+	return cell.Count * math.Pow(0.5, age.Seconds()/h.HalfLife.Seconds())
+}
+
+// Update integrates the player position and records the monsters visible on the minimap.
+// moving reports whether the player walked forward since the last update.
+func (h *HeatmapTracker) Update(direction DirectionInfo, moving bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if !h.lastUpdate.IsZero() && moving {
+		distance := now.Sub(h.lastUpdate).Seconds() * heatmapMoveSpeed
+		rad := direction.CurrentAngle * math.Pi / 180
+		h.X += math.Cos(rad) * distance
+		h.Y += math.Sin(rad) * distance
+	}
+	h.Angle = direction.CurrentAngle
+	h.lastUpdate = now
+
+	if len(direction.Monsters) > 0 && now.Sub(h.lastSample) >= heatmapSampleInterval {
+		h.lastSample = now
+		for _, monster := range direction.Monsters {
+			key := cellKey(cellOf(h.X+float64(monster.X), h.Y+float64(monster.Y)))
+			cell, ok := h.Cells[key]
+			if !ok {
+				cell = &HeatmapCell{}
+				h.Cells[key] = cell
+			}
+			cell.Count = h.decayed(cell, now) + 1
+			cell.Updated = now
+		}
+	}
+
+	if now.Sub(h.lastSave) >= heatmapSaveInterval {
+		h.prune(now)
+		h.save()
+		h.lastSave = now
+	}
+}
+
+// Cell returns the grid cell of the estimated player position
+func (h *HeatmapTracker) Cell() image.Point {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return cellOf(h.X, h.Y)
+}
+
+// ScreenCell returns the grid cell of something seen on screen right and ahead
+// screen pixels from the player, turned into the direction the player faces
+func (h *HeatmapTracker) ScreenCell(right, ahead float64) image.Point {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rad := h.Angle * math.Pi / 180
+	forwardX, forwardY := math.Cos(rad), math.Sin(rad)
+	x := h.X + (forwardX*ahead-forwardY*right)*heatmapScreenScale
+	y := h.Y + (forwardY*ahead+forwardX*right)*heatmapScreenScale
+	return cellOf(x, y)
+}
+
+// Position returns the estimated player position
+func (h *HeatmapTracker) Position() (float64, float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.X, h.Y
+}
+
+// SetPosition moves the estimated player position, e.g. to the respawn point
+// after dying (dead reckoning cannot follow the teleport)
+func (h *HeatmapTracker) SetPosition(x, y float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.X, h.Y = x, y
+}
+
+// Best returns the densest cell within radius cells of the player, excluding the
+// current cell and cells for which skip returns true (skip may be nil)
+func (h *HeatmapTracker) Best(radius int, skip func(image.Point) bool) (image.Point, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	current := cellOf(h.X, h.Y)
+	best := image.Point{}
+	bestCount := heatmapMinCount
+	found := false
+
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			cell := image.Pt(current.X+dx, current.Y+dy)
+			if skip != nil && skip(cell) {
+				continue
+			}
+			data, ok := h.Cells[cellKey(cell)]
+			if !ok {
+				continue
+			}
+			if count := h.decayed(data, now); count >= bestCount {
+				best = cell
+				bestCount = count
+				found = true
+			}
+		}
+	}
+
+	return best, found
+}
+
+// DirectionTo returns the angle (degrees, minimap coordinates) and distance (minimap pixels)
+// from the estimated player position to the center of a cell
+func (h *HeatmapTracker) DirectionTo(cell image.Point) (float64, float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	dx := (float64(cell.X)+0.5)*heatmapCellSize - h.X
+	dy := (float64(cell.Y)+0.5)*heatmapCellSize - h.Y
+	return math.Atan2(dy, dx) * 180 / math.Pi, math.Sqrt(dx*dx + dy*dy)
+}
+
+// prune drops cells that have decayed to nothing
+func (h *HeatmapTracker) prune(now time.Time) {
+	for key, cell := range h.Cells {
+		if h.decayed(cell, now) < heatmapPruneCount {
+			delete(h.Cells, key)
+		}
+	}
+}
+
+// Save writes the heatmap to Path
+func (h *HeatmapTracker) Save() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prune(time.Now())
+	return h.save()
+}
+
+// save writes the heatmap to Path (caller holds the lock)
+func (h *HeatmapTracker) save() error {
+	if h.Path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(heatmapFile{Cells: h.Cells}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal heatmap: %w", err)
+	}
+
+	if err := os.WriteFile(h.Path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write heatmap file: %w", err)
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"syscall"
 	"time"
@@ -32,7 +33,8 @@ func main() {
 	cfg.Log("Flyff Bot starting...")
 
 	// Create debug manager
-	debug := NewDebug(&cfg.Stat)
+	stat := cfg.Snapshot()
+	debug := NewDebug(&stat)
 	defer debug.Close()
 
 	// Create debug windows (must be on main thread)
@@ -61,9 +63,27 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Clear Avoidance List action: a signal, or the clear_avoidance file next to stat.json
+	clearChan := make(chan os.Signal, 1)
+	if len(clearAvoidanceSignals) > 0 {
+		signal.Notify(clearChan, clearAvoidanceSignals...)
+	}
+	clearTicker := time.NewTicker(configWatchInterval)
+	defer clearTicker.Stop()
+	clearAvoidance := func() {
+		if err := farming.Avoidance.Clear(); err != nil {
+			cfg.Log("Failed to clear avoidance list: %v", err)
+		} else {
+			cfg.Log("Avoidance list cleared")
+		}
+	}
+
 	// Start farming in a goroutine
 	go farming.Start()
 
+	// Reload stat.json whenever it is edited
+	go cfg.WatchConfig()
+
 	// Main loop: process debug updates while waiting for shutdown
 	ticker := time.NewTicker(16 * time.Millisecond) // ~60 FPS
 	defer ticker.Stop()
@@ -73,6 +93,12 @@ func main() {
 		case <-sigChan:
 			cfg.Log("Received shutdown signal, stopping...")
 			goto shutdown
+		case <-clearChan:
+			clearAvoidance()
+		case <-clearTicker.C:
+			if AvoidanceClearRequested(filepath.Dir(cfg.StatPath)) {
+				clearAvoidance()
+			}
 		case <-ticker.C:
 			// Process debug window updates on main thread
 			debug.ProcessUpdates()
@@ -80,6 +106,11 @@ func main() {
 	}
 
 shutdown:
+	// Save mob density heatmap
+	if err := farming.Heatmap.Save(); err != nil {
+		cfg.Log("Failed to save heatmap: %v", err)
+	}
+
 	// Save cookies before exit
 	if err := browser.SaveCookie(cfg); err != nil {
 		cfg.Log("Failed to save cookies: %v", err)
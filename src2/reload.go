@@ -0,0 +1,182 @@
+// Package main - reload.go
+//
+// This file re-reads stat.json while the bot runs, so settings can be tuned without a restart.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// configWatchInterval is how often stat.json is checked for changes
+const configWatchInterval = time.Second
+
+// restartOnlyKeys are stat.json keys only read at startup
+var restartOnlyKeys = []string{"debug", "regions", "status", "heatmap", "avoidance", "cookies", "log", "browserLog",
+	"settings.heatmapHalfLife", "settings.avoidanceTTL"}
+
+// WatchConfig reloads stat.json whenever its modification time changes.
+// Runs until the process exits.
+func (c *Config) WatchConfig() {
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		info, err := os.Stat(c.StatPath)
+		if err != nil {
+			continue
+		}
+
+		c.mu.RLock()
+		changed := !info.ModTime().Equal(c.statModTime)
+		c.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		if err := c.ReloadConfig(); err != nil {
+			c.Log("Config reload failed, keeping the current settings: %v", err)
+		}
+	}
+}
+
+// ReloadConfig re-reads stat.json and applies it if it parses and validates.
+// A half-written or invalid file leaves the current settings untouched.
+// Runtime status (Status, wait contexts, cooldowns, Disable) is kept. Readers
+// see the new settings through Config.Snapshot.
+func (c *Config) ReloadConfig() error {
+	info, err := os.Stat(c.StatPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat config file: %w", err)
+	}
+
+	// Remember the version even if it is invalid, so it is not retried every tick
+	c.mu.Lock()
+	c.statModTime = info.ModTime()
+	c.mu.Unlock()
+
+	data, err := os.ReadFile(c.StatPath)
+	if err != nil {
+		return fmt.Errorf("failed to read stat file: %w", err)
+	}
+
+	// Parse into a fresh Stat like at startup, never into the live one
+	next, _, err := decodeStat(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse stat file: %w", err)
+	}
+	if err := next.validate(); err != nil {
+		return fmt.Errorf("invalid stat file: %w", err)
+	}
+
+	c.mu.Lock()
+	prev := c.Stat
+	c.Stat = next
+	c.mu.Unlock()
+
+	changes := diffStat(prev, next)
+	if len(changes) == 0 {
+		return nil
+	}
+	c.Log("Config reloaded, %d changed: %s", len(changes), strings.Join(changes, ", "))
+
+	for _, change := range changes {
+		for _, key := range restartOnlyKeys {
+			if change == key || strings.HasPrefix(change, key+".") || strings.HasPrefix(change, key+" ") {
+				c.Log("Config key %s only applies after a restart", key)
+			}
+		}
+	}
+	return nil
+}
+
+// validate rejects settings the bot cannot run with
+func (s *Stat) validate() error {
+	if s.Interval < 0 {
+		return fmt.Errorf("interval must not be negative (got %d)", s.Interval)
+	}
+	for i, slot := range s.Slots {
+		if slot.Page < 1 || slot.Page > 9 {
+			return fmt.Errorf("slots[%d]: page must be 1-9 (got %d)", i, slot.Page)
+		}
+		if slot.Slot < 0 || slot.Slot > 9 {
+			return fmt.Errorf("slots[%d]: slot must be 0-9 (got %d)", i, slot.Slot)
+		}
+	}
+	return nil
+}
+
+// diffStat lists the stat.json keys that differ as "key (old -> new)", sorted by key
+func diffStat(prev, next Stat) []string {
+	before := flattenStat(prev)
+	after := flattenStat(next)
+
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var changes []string
+	for key := range keys {
+		old, hadOld := before[key]
+		value, hasNew := after[key]
+		if hadOld && hasNew && old == value {
+			continue
+		}
+		if !hadOld {
+			old = "-"
+		}
+		if !hasNew {
+			value = "-"
+		}
+		changes = append(changes, fmt.Sprintf("%s (%s -> %s)", key, old, value))
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// flattenStat returns every leaf value of the stat JSON by dotted key (e.g. "attack.escapeHp", "slots[2].enable")
+func flattenStat(stat Stat) map[string]string {
+	out := make(map[string]string)
+
+	data, err := json.Marshal(stat)
+	if err != nil {
+		return out
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return out
+	}
+
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				walk(key, child)
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				out[prefix] = "[]"
+			}
+			for i, child := range v {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), child)
+			}
+		default:
+			encoded, _ := json.Marshal(v)
+			out[prefix] = string(encoded)
+		}
+	}
+	walk("", tree)
+	return out
+}